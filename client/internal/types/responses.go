@@ -40,23 +40,77 @@ type SearchEntry struct {
 	Entry
 	Score        float64    `json:"score"`
 	CreationTime *time.Time `json:"creationTime,omitempty"`
+	// MemoryID is set when the search spanned more than one memory
+	// (see SearchRequest.VaultID / MemoryIDs), identifying which memory
+	// this entry came from.
+	MemoryID string `json:"memoryId,omitempty"`
+	// Explanation is populated only when the request sets Explain.
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// TermContribution describes how much a single matched query term
+// contributed to a result's lexical score.
+type TermContribution struct {
+	Term         string  `json:"term"`
+	IDF          float64 `json:"idf"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Explanation is a score breakdown attached to a search result when the
+// request sets SearchRequest.Explain.
+type Explanation struct {
+	SemanticScore   float64            `json:"semanticScore"`
+	LexicalScore    float64            `json:"lexicalScore"`
+	FusionWeight    float64            `json:"fusionWeight"`
+	MatchedTerms    []TermContribution `json:"matchedTerms,omitempty"`
+	TopLexicalField string             `json:"topLexicalField,omitempty"`
 }
 
 // SearchContext represents a context shard in search results
 type SearchContext struct {
-	Context   json.RawMessage `json:"context"`
-	Timestamp string          `json:"timestamp"`
-	Kind      string          `json:"kind"`
-	Score     *float64        `json:"score,omitempty"`
+	Context                json.RawMessage `json:"context"`
+	Timestamp              string          `json:"timestamp"`
+	Kind                   string          `json:"kind"`
+	Score                  *float64        `json:"score,omitempty"`
+	Rank                   int             `json:"rank,omitempty"`
+	PreDiversificationRank int             `json:"preDiversificationRank,omitempty"`
+	// MemoryID is set when the search spanned more than one memory.
+	MemoryID string `json:"memoryId,omitempty"`
+	// Explanation is populated only when the request sets Explain.
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// PerMemorySummary summarizes a federated search's contribution from a
+// single memory.
+type PerMemorySummary struct {
+	EntryCount             int        `json:"entryCount"`
+	ContextCount           int        `json:"contextCount"`
+	LatestContextTimestamp *time.Time `json:"latestContextTimestamp,omitempty"`
+}
+
+// FusedHit is a single entry in the fused, cross-source ranked list returned
+// when the request opts into fusion (see SearchRequest.Fusion).
+type FusedHit struct {
+	Kind        string          `json:"kind"` // "entry" or "context"
+	Score       float64         `json:"score"`
+	Entry       *SearchEntry    `json:"entry,omitempty"`
+	Context     json.RawMessage `json:"context,omitempty"`
+	EntryRank   int             `json:"entryRank,omitempty"`
+	ContextRank int             `json:"contextRank,omitempty"`
 }
 
 // SearchResponse wraps the /api/search result
 type SearchResponse struct {
-	Entries                []SearchEntry   `json:"entries"`
-	Count                  int             `json:"count"`
-	Contexts               []SearchContext `json:"contexts,omitempty"`
-	LatestContext          json.RawMessage `json:"latestContext,omitempty"`
-	LatestContextTimestamp *time.Time      `json:"latestContextTimestamp,omitempty"`
+	Entries                []SearchEntry               `json:"entries"`
+	Count                  int                         `json:"count"`
+	Contexts               []SearchContext             `json:"contexts,omitempty"`
+	LatestContext          json.RawMessage             `json:"latestContext,omitempty"`
+	LatestContextTimestamp *time.Time                  `json:"latestContextTimestamp,omitempty"`
+	Fused                  []FusedHit                  `json:"fused,omitempty"`
+	// PerMemory and Partial are populated only for federated searches
+	// (SearchRequest.VaultID set, or MemoryID given as an array).
+	PerMemory map[string]PerMemorySummary `json:"perMemory,omitempty"`
+	Partial   bool                        `json:"partial,omitempty"`
 }
 
 // ListMemoriesResponse mirrors the backend list shape
@@ -25,7 +25,9 @@ func (sh *SearchHandler) RegisterTools(s *server.MCPServer) error {
 		mcp.WithDescription(`Performs hybrid semantic and keyword search within a memory.
 
 Parameters:
-• memory_id (required): Target memory UUID
+• memory_id (required unless vault_id or memory_ids is set): Target memory UUID
+• memory_ids (optional): Array of memory UUIDs to search across (federated search)
+• vault_id (optional): Search every memory in this vault the caller can access
 • query (required): Search query text
 • top_ke (optional): Number of entry results to return
   - Default: 5
@@ -36,6 +38,33 @@ Parameters:
 • include_raw_entries (optional): Include raw entry content in results
   - Default: false (raw entries excluded to save tokens)
   - Set to true to include full raw entry content
+• fusion (optional): Ranked-list fusion mode over the returned entries
+  - One of "rrf", "weighted", "none" (default: "none")
+  - Context shards are not folded into the fused list by this tool
+• alpha (optional): Hybrid semantic weight for this request, overriding the
+  server's configured value (range: 0-1); "weighted" fusion also scales the
+  normalized entry scores by it (default: 0.5)
+• k (optional): RRF rank-constant (default: 60)
+• filter (optional): Structured filter object pushed down into the search backend
+  - time_range: {from, to} RFC3339 timestamps
+  - kinds: restrict context shards to these kinds
+  - tags: {all_of, any_of, none_of} string arrays matched against entry tags
+  - metadata: map of key to {eq, in, gte, lte} predicate
+• diversify (optional): Apply MMR diversification to context shard candidates
+  - Default: false
+• lambda (optional): MMR relevance/diversity trade-off (default: 0.5, range: 0-1)
+  - Higher favors relevance, lower favors diversity
+• explain (optional): Attach a score breakdown to each entry and context shard
+  - Default: false (omitted entirely to preserve token footprint)
+  - Includes the semantic and lexical score components, fusion weight, matched
+    query terms with per-term IDF/contribution, and (for entries) which field
+    produced the top lexical match
+• bm25_boost (optional): Multiplier on the lexical (BM25) component of hybrid
+  scoring, applied before the alpha blend (default: 1.0, must be > 0)
+• vector_boost (optional): Multiplier on the semantic (vector) component of
+  hybrid scoring, applied before the alpha blend (default: 1.0, must be > 0)
+• variant (optional): Name of a configured search index variant to force this
+  request onto, bypassing the server's deterministic shadow-traffic routing
 
 Returns:
 • entries: Array of matching entries (size: 0 to top_ke), each with:
@@ -49,19 +78,43 @@ Returns:
   - score: Relevance score (0-1)
 
 The timestamps allow understanding temporal evolution of the memory. Context shards are sorted by relevance score descending. Entries are sorted by relevance score descending.`),
-		mcp.WithString("memory_id", mcp.Required(), mcp.Description("The UUID of the memory")),
+		mcp.WithString("memory_id", mcp.Description("The UUID of the memory (required unless vault_id or memory_ids is set)")),
+		mcp.WithArray("memory_ids", mcp.Description("Array of memory UUIDs to search across (federated search)")),
+		mcp.WithString("vault_id", mcp.Description("Search every memory in this vault the caller can access")),
 		mcp.WithString("query", mcp.Required(), mcp.Description("Search query text")),
 		mcp.WithNumber("top_ke", mcp.Description("Top-k for entries (default: 5, range: 0-25)")),
 		mcp.WithNumber("top_kc", mcp.Description("Top-k for context shards (default: 2, range: 1-10)")),
 		mcp.WithBoolean("include_raw_entries", mcp.Description("Include raw entry content in results (default: false)")),
+		mcp.WithString("fusion", mcp.Description(`Ranked-list fusion mode: "rrf", "weighted", or "none" (default: "none")`)),
+		mcp.WithNumber("alpha", mcp.Description("Hybrid semantic weight override; also scales entry scores in \"weighted\" fusion (default: 0.5, range: 0-1)")),
+		mcp.WithNumber("k", mcp.Description("RRF rank-constant (default: 60)")),
+		mcp.WithObject("filter", mcp.Description("Structured filter: time_range, kinds, tags, metadata — pushed down into the search backend")),
+		mcp.WithBoolean("diversify", mcp.Description("Apply MMR diversification to context shard candidates (default: false)")),
+		mcp.WithNumber("lambda", mcp.Description("MMR relevance/diversity trade-off (default: 0.5, range: 0-1)")),
+		mcp.WithBoolean("explain", mcp.Description("Attach a score breakdown to each result (default: false)")),
+		mcp.WithNumber("bm25_boost", mcp.Description("Multiplier on the lexical (BM25) score component (default: 1.0, must be > 0)")),
+		mcp.WithNumber("vector_boost", mcp.Description("Multiplier on the semantic (vector) score component (default: 1.0, must be > 0)")),
+		mcp.WithString("variant", mcp.Description("Force this request onto a configured search index variant, bypassing shadow routing")),
 	)
 	s.AddTool(searchTool, sh.handleSearch)
 	return nil
 }
 
 func (sh *SearchHandler) handleSearch(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-	memoryID, _ := req.RequireString("memory_id")
+	memoryID, _ := req.GetArguments()["memory_id"].(string)
+	vaultID, _ := req.GetArguments()["vault_id"].(string)
+	var memoryIDs []string
+	if raw, ok := req.GetArguments()["memory_ids"].([]interface{}); ok {
+		for _, v := range raw {
+			if s, ok := v.(string); ok {
+				memoryIDs = append(memoryIDs, s)
+			}
+		}
+	}
 	query, _ := req.RequireString("query")
+	if memoryID == "" && vaultID == "" && len(memoryIDs) == 0 {
+		return mcp.NewToolResultError("memory_id, memory_ids, or vault_id is required"), nil
+	}
 
 	// Handle top_ke parameter (default: 5, range: 0-25)
 	topKE := 5
@@ -87,12 +140,77 @@ func (sh *SearchHandler) handleSearch(ctx context.Context, req mcp.CallToolReque
 		includeRawEntries = v
 	}
 
+	// Handle fusion parameters (default: fusion disabled)
+	fusion := client.FusionNone
+	if v, ok := req.GetArguments()["fusion"].(string); ok && v != "" {
+		fusion = client.FusionMode(v)
+	}
+	var alpha *float64
+	if v, ok := req.GetArguments()["alpha"].(float64); ok {
+		alpha = &v
+	}
+	var k *int
+	if v, ok := req.GetArguments()["k"].(float64); ok {
+		kk := int(v)
+		k = &kk
+	}
+
+	// Handle filter parameter (passed through as a structured object)
+	var filter *client.Filter
+	if raw, ok := req.GetArguments()["filter"]; ok {
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid filter: %v", err)), nil
+		}
+		var f client.Filter
+		if err := json.Unmarshal(b, &f); err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid filter: %v", err)), nil
+		}
+		filter = &f
+	}
+
+	diversify := false
+	if v, ok := req.GetArguments()["diversify"].(bool); ok {
+		diversify = v
+	}
+	var lambda *float64
+	if v, ok := req.GetArguments()["lambda"].(float64); ok {
+		lambda = &v
+	}
+
+	explain := false
+	if v, ok := req.GetArguments()["explain"].(bool); ok {
+		explain = v
+	}
+
+	var bm25Boost *float64
+	if v, ok := req.GetArguments()["bm25_boost"].(float64); ok {
+		bm25Boost = &v
+	}
+	var vectorBoost *float64
+	if v, ok := req.GetArguments()["vector_boost"].(float64); ok {
+		vectorBoost = &v
+	}
+	variant, _ := req.GetArguments()["variant"].(string)
+
 	resp, err := sh.client.Search(ctx, client.SearchRequest{
 		MemoryID:          memoryID,
+		MemoryIDs:         memoryIDs,
+		VaultID:           vaultID,
 		Query:             query,
 		TopKE:             &topKE,
 		TopKC:             &topKC,
 		IncludeRawEntries: includeRawEntries,
+		Fusion:            fusion,
+		Alpha:             alpha,
+		K:                 k,
+		Filter:            filter,
+		Diversify:         diversify,
+		Lambda:            lambda,
+		Explain:           explain,
+		BM25Boost:         bm25Boost,
+		VectorBoost:       vectorBoost,
+		Variant:           variant,
 	})
 	if err != nil {
 		return mcp.NewToolResultError(fmt.Sprintf("search failed: %v", err)), nil
@@ -106,6 +224,13 @@ func (sh *SearchHandler) handleSearch(ctx context.Context, req mcp.CallToolReque
 		"latestContextTimestamp": resp.LatestContextTimestamp,
 		"contexts":               resp.Contexts,
 	}
+	if len(resp.Fused) > 0 {
+		payload["fused"] = resp.Fused
+	}
+	if len(resp.PerMemory) > 0 {
+		payload["perMemory"] = resp.PerMemory
+		payload["partial"] = resp.Partial
+	}
 	b, _ := json.MarshalIndent(payload, "", "  ")
 	return mcp.NewToolResultText(string(b)), nil
 }
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex/fuzzy"
+)
+
+// FuzzyConfig controls the cross-scope fuzzy-match endpoint
+// (POST /api/search/fuzzy). This is a completely different use case from
+// HandleSearch: a fast, typo-tolerant "find things by name" lookup over
+// bounded candidate scans, not vector/hybrid semantic recall — it never
+// calls SearchHandler.emb.
+type FuzzyConfig struct {
+	Enabled       bool `json:"enabled"`
+	MinTermLength int  `json:"min_term_length"`
+	LimitQuery    int  `json:"limit_query"`
+	LimitResults  int  `json:"limit_results"`
+}
+
+// DefaultFuzzyConfig returns sane operational caps: enabled, a 2-character
+// minimum query, and a bounded per-context-type scan/result size.
+func DefaultFuzzyConfig() FuzzyConfig {
+	return FuzzyConfig{Enabled: true, MinTermLength: 2, LimitQuery: 500, LimitResults: 10}
+}
+
+// fuzzyContextTypes are the resource types HandleFuzzySearch can scan.
+// "all" expands to every type that has a configured Source.
+var fuzzyContextTypes = []string{"vaults", "memories", "entries", "contexts"}
+
+// FuzzySearchRequest is the payload for POST /api/search/fuzzy.
+type FuzzySearchRequest struct {
+	Text string `json:"text"`
+	// Context is one of "vaults", "memories", "entries", "contexts", or
+	// "all" (default). Disabled or unconfigured context types are skipped.
+	Context string `json:"context"`
+	// Namespace optionally scopes the scan (e.g. to a single vault or
+	// memory ID); "" searches everything the actor can access.
+	Namespace string `json:"namespace"`
+}
+
+// FuzzyMatch is one fuzzy-matched hit. Scope carries the full breadcrumb
+// path (vault title -> memory title -> entry timestamp) so a UI can render
+// it without a follow-up lookup.
+type FuzzyMatch struct {
+	ID    string `json:"id"`
+	Scope string `json:"scope"`
+}
+
+// FuzzySearchResponse groups matches and scan-truncation flags by context
+// type. Index echoes the namespace the search was scoped to, so a caller
+// that requested "all" can tell which namespace the breadcrumbs are
+// relative to.
+type FuzzySearchResponse struct {
+	Matches     map[string][]FuzzyMatch `json:"matches"`
+	Truncations map[string]bool         `json:"truncations"`
+	Index       string                  `json:"index"`
+}
+
+// contextTypes resolves r.Context to the concrete list of context types to
+// scan, defaulting "" and "all" to every known type.
+func (r *FuzzySearchRequest) contextTypes() ([]string, error) {
+	switch r.Context {
+	case "", "all":
+		return fuzzyContextTypes, nil
+	case "vaults", "memories", "entries", "contexts":
+		return []string{r.Context}, nil
+	default:
+		return nil, fmt.Errorf("context must be one of %q, %q, %q, %q, %q", "vaults", "memories", "entries", "contexts", "all")
+	}
+}
+
+// HandleFuzzySearch handles POST /api/search/fuzzy: typo-tolerant
+// prefix/substring matching across vaults, memories, entry summaries, and
+// context shards for the authenticated actor.
+func (h *SearchHandler) HandleFuzzySearch(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+	if !h.fuzzyCfg.Enabled {
+		respond.WriteError(w, http.StatusServiceUnavailable, "fuzzy search not configured")
+		return
+	}
+
+	var req FuzzySearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	req.Text = strings.TrimSpace(req.Text)
+	if len(req.Text) < h.fuzzyCfg.MinTermLength {
+		respond.WriteBadRequest(w, fmt.Sprintf("text must be at least %d characters", h.fuzzyCfg.MinTermLength))
+		return
+	}
+	contextTypes, err := req.contextTypes()
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	matches := make(map[string][]FuzzyMatch, len(contextTypes))
+	truncations := make(map[string]bool, len(contextTypes))
+	for _, ct := range contextTypes {
+		src, ok := h.fuzzySources[ct]
+		if !ok {
+			continue // no source configured for this context type: treat as disabled
+		}
+		hits, truncated, err := fuzzy.Search(r.Context(), src, actorInfo.ActorID, req.Namespace, req.Text, h.fuzzyCfg.LimitQuery, h.fuzzyCfg.LimitResults)
+		if err != nil {
+			log.Error().Err(err).Str("context", ct).Msg("fuzzy search failed")
+			respond.WriteError(w, http.StatusInternalServerError, "fuzzy search unavailable")
+			return
+		}
+		out := make([]FuzzyMatch, len(hits))
+		for i, m := range hits {
+			out[i] = FuzzyMatch{ID: m.ID, Scope: m.Scope}
+		}
+		matches[ct] = out
+		truncations[ct] = truncated
+	}
+
+	respond.WriteJSON(w, http.StatusOK, FuzzySearchResponse{Matches: matches, Truncations: truncations, Index: req.Namespace})
+}
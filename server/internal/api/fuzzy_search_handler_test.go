@@ -0,0 +1,127 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex/fuzzy"
+)
+
+type fakeFuzzySource struct {
+	candidates []fuzzy.Candidate
+}
+
+func (s *fakeFuzzySource) Candidates(ctx context.Context, actorID, namespace string) ([]fuzzy.Candidate, error) {
+	return s.candidates, nil
+}
+
+func newFuzzyTestHandler(t *testing.T, cfg FuzzyConfig, sources map[string]fuzzy.Source) *SearchHandler {
+	t.Helper()
+	h, err := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+	if err != nil {
+		t.Fatalf("NewSearchHandler: %v", err)
+	}
+	h.SetFuzzyConfig(cfg, sources)
+	return h
+}
+
+func TestHandleFuzzySearch_DisabledReturns503(t *testing.T) {
+	h := newFuzzyTestHandler(t, FuzzyConfig{}, nil)
+
+	body := bytes.NewBufferString(`{"text":"proj","context":"vaults"}`)
+	req := httptest.NewRequest("POST", "/api/search/fuzzy", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleFuzzySearch(w, req)
+
+	if w.Code != 503 {
+		t.Fatalf("expected 503 when fuzzy search is disabled, got %d", w.Code)
+	}
+}
+
+func TestHandleFuzzySearch_ShortQueryRejected(t *testing.T) {
+	h := newFuzzyTestHandler(t, DefaultFuzzyConfig(), nil)
+
+	body := bytes.NewBufferString(`{"text":"a","context":"vaults"}`)
+	req := httptest.NewRequest("POST", "/api/search/fuzzy", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleFuzzySearch(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for a query below min_term_length, got %d", w.Code)
+	}
+}
+
+func TestHandleFuzzySearch_UnknownContextRejected(t *testing.T) {
+	h := newFuzzyTestHandler(t, DefaultFuzzyConfig(), nil)
+
+	body := bytes.NewBufferString(`{"text":"proj","context":"widgets"}`)
+	req := httptest.NewRequest("POST", "/api/search/fuzzy", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleFuzzySearch(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for an unknown context type, got %d", w.Code)
+	}
+}
+
+func TestHandleFuzzySearch_DisabledContextTypeIsSkipped(t *testing.T) {
+	sources := map[string]fuzzy.Source{
+		"vaults": &fakeFuzzySource{candidates: []fuzzy.Candidate{{ID: "v1", Text: "Project Vault", Scope: "Project Vault"}}},
+	}
+	h := newFuzzyTestHandler(t, DefaultFuzzyConfig(), sources)
+
+	body := bytes.NewBufferString(`{"text":"proj","context":"all"}`)
+	req := httptest.NewRequest("POST", "/api/search/fuzzy", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleFuzzySearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp FuzzySearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if _, ok := resp.Matches["memories"]; ok {
+		t.Fatalf("expected no entry for an unconfigured context type, got %+v", resp.Matches)
+	}
+	if len(resp.Matches["vaults"]) != 1 || resp.Matches["vaults"][0].ID != "v1" {
+		t.Fatalf("expected a single vault match, got %+v", resp.Matches["vaults"])
+	}
+}
+
+func TestHandleFuzzySearch_ReturnsBreadcrumbScopeAndTruncationFlag(t *testing.T) {
+	sources := map[string]fuzzy.Source{
+		"memories": &fakeFuzzySource{candidates: []fuzzy.Candidate{
+			{ID: "m1", Text: "Project Mercury", Scope: "Vault Alpha > Project Mercury"},
+		}},
+	}
+	h := newFuzzyTestHandler(t, FuzzyConfig{Enabled: true, MinTermLength: 2, LimitQuery: 1, LimitResults: 10}, sources)
+
+	body := bytes.NewBufferString(`{"text":"Project","context":"memories"}`)
+	req := httptest.NewRequest("POST", "/api/search/fuzzy", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleFuzzySearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp FuzzySearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Matches["memories"]) != 1 || resp.Matches["memories"][0].Scope != "Vault Alpha > Project Mercury" {
+		t.Fatalf("expected breadcrumb scope to be preserved, got %+v", resp.Matches["memories"])
+	}
+	if resp.Truncations["memories"] {
+		t.Fatalf("did not expect truncation with a single candidate under limit_query")
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// AnswerSynthesizer produces a hypothetical answer to a query. Embedding
+// that answer, rather than the (often short, keyword-like) query itself,
+// tends to land closer to relevant documents in vector space — the HyDE
+// (Hypothetical Document Embeddings) technique. Implementations typically
+// wrap a configured LLM completion call.
+type AnswerSynthesizer interface {
+	Synthesize(ctx context.Context, query string) (string, error)
+}
+
+// HyDEHook is a built-in QueryHook demonstrating the extension point end
+// to end: BeforeEmbed synthesizes a hypothetical answer via a configured
+// AnswerSynthesizer and embeds that instead of the raw query, while
+// leaving SearchRequest.Query untouched so the BM25 side of the hybrid
+// blend still matches against what the caller actually typed.
+type HyDEHook struct {
+	synth AnswerSynthesizer
+}
+
+// NewHyDEHook builds a HyDEHook backed by synth.
+func NewHyDEHook(synth AnswerSynthesizer) *HyDEHook {
+	return &HyDEHook{synth: synth}
+}
+
+func (h *HyDEHook) Name() string { return "hyde" }
+
+func (h *HyDEHook) BeforeEmbed(ctx context.Context, req *SearchRequest) (*SearchRequest, *model.Filter, error) {
+	answer, err := h.synth.Synthesize(ctx, req.Query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("synthesizing hypothetical answer: %w", err)
+	}
+	rewritten := *req
+	rewritten.SetEmbedText(answer)
+	return &rewritten, nil, nil
+}
+
+// AfterSearch is a no-op: HyDEHook only affects what gets embedded.
+func (h *HyDEHook) AfterSearch(ctx context.Context, req *SearchRequest, hits []model.SearchHit, ctxHits []model.ContextHit) ([]model.SearchHit, []model.ContextHit, error) {
+	return nil, nil, ErrSkip
+}
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/outbox"
+)
+
+// outboxStore is the subset of *outbox.Worker the admin handler needs. It
+// exists so tests can substitute a fake store instead of a real database,
+// the same way SearchHandler depends on searchindex.Index rather than a
+// concrete type.
+type outboxStore interface {
+	ListJobs(ctx context.Context, opFilter string, limit int) ([]outbox.JobSummary, error)
+	GetJob(ctx context.Context, id int64) (*outbox.JobDetail, error)
+	RetryJob(ctx context.Context, id int64) error
+	RetryDeadLetterJob(ctx context.Context, id int64) error
+	DeleteDeadLetterJob(ctx context.Context, id int64) error
+	GetStats(ctx context.Context) (outbox.Stats, error)
+}
+
+// OutboxAdminHandler exposes an internal HTTP API, mounted under
+// /admin/outbox, for inspecting and replaying jobs in the transactional
+// outbox. It wraps the same *outbox.Worker the background drain loop uses,
+// so an operator sees exactly the state the worker will act on next.
+type OutboxAdminHandler struct {
+	store      outboxStore
+	authorizer auth.Authorizer
+}
+
+// NewOutboxAdminHandler constructs an OutboxAdminHandler backed by worker.
+func NewOutboxAdminHandler(worker *outbox.Worker, authorizer auth.Authorizer) *OutboxAdminHandler {
+	return &OutboxAdminHandler{store: worker, authorizer: authorizer}
+}
+
+// RegisterRoutes wires the admin endpoints onto mux under /admin/outbox.
+func (h *OutboxAdminHandler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/admin/outbox/jobs", h.handleJobsCollection)
+	mux.HandleFunc("/admin/outbox/jobs/", h.handleJobsItem)
+	mux.HandleFunc("/admin/outbox/dead-letter/", h.handleDeadLetterItem)
+	mux.HandleFunc("/admin/outbox/stats", h.handleStats)
+}
+
+func (h *OutboxAdminHandler) authorize(w http.ResponseWriter, r *http.Request) (*auth.ActorInfo, bool) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return nil, false
+	}
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "outbox.admin", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return nil, false
+	}
+	return actorInfo, true
+}
+
+// handleJobsCollection serves GET /admin/outbox/jobs?op=&status=&limit=.
+// status is accepted but currently only "pending" jobs are tracked here;
+// dead-lettered jobs are listed via the dead-letter endpoints instead.
+func (h *OutboxAdminHandler) handleJobsCollection(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respond.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := 0
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			respond.WriteBadRequest(w, "limit must be an integer")
+			return
+		}
+		limit = n
+	}
+
+	jobs, err := h.store.ListJobs(r.Context(), r.URL.Query().Get("op"), limit)
+	if err != nil {
+		log.Error().Err(err).Msg("listing outbox jobs failed")
+		respond.WriteError(w, http.StatusInternalServerError, "listing jobs unavailable")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"jobs": jobs, "count": len(jobs)})
+}
+
+// handleJobsItem serves GET /admin/outbox/jobs/{id} and
+// POST /admin/outbox/jobs/{id}/retry.
+func (h *OutboxAdminHandler) handleJobsItem(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/outbox/jobs/")
+	id, retry, err := parseItemPath(rest)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	switch {
+	case retry && r.Method == http.MethodPost:
+		if err := h.store.RetryJob(r.Context(), id); err != nil {
+			writeWorkerError(w, err, "retrying job unavailable")
+			return
+		}
+		respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "scheduled"})
+	case !retry && r.Method == http.MethodGet:
+		detail, err := h.store.GetJob(r.Context(), id)
+		if err != nil {
+			writeWorkerError(w, err, "fetching job unavailable")
+			return
+		}
+		respond.WriteJSON(w, http.StatusOK, detail)
+	default:
+		respond.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleDeadLetterItem serves POST /admin/outbox/dead-letter/{id}/retry and
+// DELETE /admin/outbox/dead-letter/{id}.
+func (h *OutboxAdminHandler) handleDeadLetterItem(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+
+	rest := strings.TrimPrefix(r.URL.Path, "/admin/outbox/dead-letter/")
+	id, retry, err := parseItemPath(rest)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+
+	switch {
+	case retry && r.Method == http.MethodPost:
+		if err := h.store.RetryDeadLetterJob(r.Context(), id); err != nil {
+			writeWorkerError(w, err, "retrying dead-letter job unavailable")
+			return
+		}
+		respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "requeued"})
+	case !retry && r.Method == http.MethodDelete:
+		if err := h.store.DeleteDeadLetterJob(r.Context(), id); err != nil {
+			writeWorkerError(w, err, "deleting dead-letter job unavailable")
+			return
+		}
+		respond.WriteJSON(w, http.StatusOK, map[string]interface{}{"id": id, "status": "deleted"})
+	default:
+		respond.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleStats serves GET /admin/outbox/stats.
+func (h *OutboxAdminHandler) handleStats(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.authorize(w, r); !ok {
+		return
+	}
+	if r.Method != http.MethodGet {
+		respond.WriteError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	stats, err := h.store.GetStats(r.Context())
+	if err != nil {
+		log.Error().Err(err).Msg("computing outbox stats failed")
+		respond.WriteError(w, http.StatusInternalServerError, "stats unavailable")
+		return
+	}
+	respond.WriteJSON(w, http.StatusOK, stats)
+}
+
+// parseItemPath splits a "{id}" or "{id}/retry" path suffix into the job id
+// and whether the retry action was requested.
+func parseItemPath(rest string) (id int64, retry bool, err error) {
+	rest = strings.Trim(rest, "/")
+	idPart := rest
+	if before, after, found := strings.Cut(rest, "/"); found {
+		idPart = before
+		retry = after == "retry"
+		if !retry {
+			return 0, false, errBadItemPath
+		}
+	}
+	id, parseErr := strconv.ParseInt(idPart, 10, 64)
+	if parseErr != nil {
+		return 0, false, errBadItemPath
+	}
+	return id, retry, nil
+}
+
+var errBadItemPath = errors.New("invalid job id in path")
+
+func writeWorkerError(w http.ResponseWriter, err error, serviceErrMsg string) {
+	switch err {
+	case outbox.ErrJobNotFound:
+		respond.WriteError(w, http.StatusNotFound, "job not found")
+	case outbox.ErrNotRescheduleEligible:
+		respond.WriteError(w, http.StatusConflict, "job was dead-lettered for a non-retryable reason; it will not be replayed")
+	default:
+		log.Error().Err(err).Msg(serviceErrMsg)
+		respond.WriteError(w, http.StatusInternalServerError, serviceErrMsg)
+	}
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/outbox"
+)
+
+// fakeOutboxStore is a hand-rolled outboxStore for tests, mirroring mockSearch.
+type fakeOutboxStore struct {
+	jobs       map[int64]*outbox.JobDetail
+	deadLetter map[int64]error // non-nil means RetryDeadLetterJob should fail for this id
+	retried    []int64
+}
+
+func newFakeOutboxStore() *fakeOutboxStore {
+	return &fakeOutboxStore{jobs: map[int64]*outbox.JobDetail{}, deadLetter: map[int64]error{}}
+}
+
+func (f *fakeOutboxStore) ListJobs(ctx context.Context, opFilter string, limit int) ([]outbox.JobSummary, error) {
+	var out []outbox.JobSummary
+	for _, d := range f.jobs {
+		if opFilter == "" || d.Op == opFilter {
+			out = append(out, d.JobSummary)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeOutboxStore) GetJob(ctx context.Context, id int64) (*outbox.JobDetail, error) {
+	d, ok := f.jobs[id]
+	if !ok {
+		return nil, outbox.ErrJobNotFound
+	}
+	return d, nil
+}
+
+func (f *fakeOutboxStore) RetryJob(ctx context.Context, id int64) error {
+	if _, ok := f.jobs[id]; !ok {
+		return outbox.ErrJobNotFound
+	}
+	f.retried = append(f.retried, id)
+	return nil
+}
+
+func (f *fakeOutboxStore) RetryDeadLetterJob(ctx context.Context, id int64) error {
+	if err, ok := f.deadLetter[id]; ok {
+		if err != nil {
+			return err
+		}
+		f.retried = append(f.retried, id)
+		return nil
+	}
+	return outbox.ErrJobNotFound
+}
+
+func (f *fakeOutboxStore) DeleteDeadLetterJob(ctx context.Context, id int64) error {
+	if _, ok := f.deadLetter[id]; !ok {
+		return outbox.ErrJobNotFound
+	}
+	delete(f.deadLetter, id)
+	return nil
+}
+
+func (f *fakeOutboxStore) GetStats(ctx context.Context) (outbox.Stats, error) {
+	return outbox.Stats{PendingCount: len(f.jobs), DeadLetterCount: len(f.deadLetter)}, nil
+}
+
+func newAdminTestHandler(store outboxStore) *OutboxAdminHandler {
+	return &OutboxAdminHandler{store: store, authorizer: &mockAuthorizer{}}
+}
+
+func TestOutboxAdmin_RetryDeadLetter_TransportErrorJobIsReplayed(t *testing.T) {
+	store := newFakeOutboxStore()
+	store.deadLetter[1] = nil // eligible for replay
+	h := newAdminTestHandler(store)
+
+	req := httptest.NewRequest("POST", "/admin/outbox/dead-letter/1/retry", bytes.NewReader(nil))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.handleDeadLetterItem(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.retried) != 1 || store.retried[0] != 1 {
+		t.Fatalf("expected job 1 to be replayed, got %v", store.retried)
+	}
+}
+
+func TestOutboxAdmin_RetryDeadLetter_TerminalErrorJobIsRejected(t *testing.T) {
+	store := newFakeOutboxStore()
+	store.deadLetter[2] = outbox.ErrNotRescheduleEligible // e.g. dead-lettered on a 422 already-exists
+	h := newAdminTestHandler(store)
+
+	req := httptest.NewRequest("POST", "/admin/outbox/dead-letter/2/retry", bytes.NewReader(nil))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.handleDeadLetterItem(w, req)
+
+	if w.Code != 409 {
+		t.Fatalf("expected 409 Conflict, got %d: %s", w.Code, w.Body.String())
+	}
+	if len(store.retried) != 0 {
+		t.Fatalf("expected no replay for a non-retryable job, got %v", store.retried)
+	}
+}
+
+func TestOutboxAdmin_GetJob_NotFound(t *testing.T) {
+	store := newFakeOutboxStore()
+	h := newAdminTestHandler(store)
+
+	req := httptest.NewRequest("GET", "/admin/outbox/jobs/99", bytes.NewReader(nil))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.handleJobsItem(w, req)
+
+	if w.Code != 404 {
+		t.Fatalf("expected 404, got %d", w.Code)
+	}
+}
+
+func TestOutboxAdmin_Stats_ReportsQueueDepth(t *testing.T) {
+	store := newFakeOutboxStore()
+	store.jobs[1] = &outbox.JobDetail{JobSummary: outbox.JobSummary{ID: 1}}
+	h := newAdminTestHandler(store)
+
+	req := httptest.NewRequest("GET", "/admin/outbox/stats", bytes.NewReader(nil))
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.handleStats(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// ErrSkip is returned by a QueryHook to mean "nothing to contribute to this
+// request," not a failure. HookChain treats it as a no-op and moves on to
+// the next hook instead of surfacing it as an error.
+var ErrSkip = errors.New("query hook: skip")
+
+// QueryHook is an optional, named extension point around HandleSearch's
+// embed and search calls, letting operators register rewriters (query
+// expansion, HyDE-style pseudo-answer embedding, stopword filtering, PII
+// redaction) and post-processors (cross-encoder rerank, dedupe) without
+// forking the handler.
+type QueryHook interface {
+	// Name identifies the hook in logs and metrics.
+	Name() string
+
+	// BeforeEmbed runs once per request before h.emb.Embed. Most hooks
+	// should mutate req.Query directly, which affects both the embedded
+	// text and the BM25 lexical match (query expansion, stopword
+	// filtering, PII redaction). A hook that wants to change only what
+	// gets embedded — HyDEHook substituting a synthesized answer — should
+	// call req.SetEmbedText instead, so Query keeps driving BM25.
+	// extraFilters, if non-nil, replaces the request's current filter.
+	// Returning ErrSkip leaves the request untouched.
+	BeforeEmbed(ctx context.Context, req *SearchRequest) (rewritten *SearchRequest, extraFilters *model.Filter, err error)
+
+	// AfterSearch runs once per request after idx.Search/SearchContexts
+	// have produced the request's (possibly federation-merged) candidate
+	// hits. It may return reranked/deduped hits and context hits; a nil
+	// slice means "unchanged". Returning ErrSkip leaves the results
+	// untouched.
+	AfterSearch(ctx context.Context, req *SearchRequest, hits []model.SearchHit, ctxHits []model.ContextHit) (rerankedHits []model.SearchHit, rerankedCtx []model.ContextHit, err error)
+}
+
+var (
+	hookLatencySeconds = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mycelian_search_hook_latency_seconds",
+		Help:    "Latency of each QueryHook invocation, by hook name and stage.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"hook", "stage"})
+	hookErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mycelian_search_hook_errors_total",
+		Help: "QueryHook invocations that returned a non-skip error, by hook name and stage.",
+	}, []string{"hook", "stage"})
+)
+
+// HookChain runs a sequence of QueryHooks around a search request, each
+// bounded by a per-hook timeout so a slow or hung hook can't stall the
+// request indefinitely. Hooks run in registration order; each sees the
+// previous hook's output.
+type HookChain struct {
+	hooks   []QueryHook
+	timeout time.Duration
+}
+
+// NewHookChain builds a HookChain from hooks, run in registration order.
+// perHookTimeout bounds each individual hook invocation; 0 disables the
+// timeout.
+func NewHookChain(perHookTimeout time.Duration, hooks ...QueryHook) *HookChain {
+	return &HookChain{hooks: hooks, timeout: perHookTimeout}
+}
+
+func (c *HookChain) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// BeforeEmbed runs every hook's BeforeEmbed in order, threading each hook's
+// rewritten request into the next. A hook returning ErrSkip is bypassed;
+// any other error aborts the chain and is returned to the caller (surfaced
+// as a 500 by HandleSearch).
+func (c *HookChain) BeforeEmbed(ctx context.Context, req *SearchRequest) (*SearchRequest, error) {
+	out := req
+	for _, hook := range c.hooks {
+		hctx, cancel := c.withTimeout(ctx)
+		start := time.Now()
+		rewritten, extraFilters, err := hook.BeforeEmbed(hctx, out)
+		cancel()
+		c.record(hook.Name(), "before_embed", start, err)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hook %s: %w", hook.Name(), err)
+		}
+		if rewritten != nil {
+			out = rewritten
+		}
+		if extraFilters != nil {
+			out.Filter = extraFilters
+		}
+	}
+	return out, nil
+}
+
+// AfterSearch runs every hook's AfterSearch in order, threading each hook's
+// reranked output into the next.
+func (c *HookChain) AfterSearch(ctx context.Context, req *SearchRequest, hits []model.SearchHit, ctxHits []model.ContextHit) ([]model.SearchHit, []model.ContextHit, error) {
+	originalHitCount := len(hits)
+	for _, hook := range c.hooks {
+		hctx, cancel := c.withTimeout(ctx)
+		start := time.Now()
+		rerankedHits, rerankedCtx, err := hook.AfterSearch(hctx, req, hits, ctxHits)
+		cancel()
+		c.record(hook.Name(), "after_search", start, err)
+		if errors.Is(err, ErrSkip) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("hook %s: %w", hook.Name(), err)
+		}
+		if rerankedHits != nil {
+			hits = rerankedHits
+		}
+		if rerankedCtx != nil {
+			ctxHits = rerankedCtx
+		}
+	}
+	if len(hits) != originalHitCount {
+		log.Debug().Int("before", originalHitCount).Int("after", len(hits)).Msg("query hooks changed entry hit count")
+	}
+	return hits, ctxHits, nil
+}
+
+func (c *HookChain) record(name, stage string, start time.Time, err error) {
+	hookLatencySeconds.WithLabelValues(name, stage).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, ErrSkip) {
+		hookErrorsTotal.WithLabelValues(name, stage).Inc()
+	}
+	log.Debug().Str("hook", name).Str("stage", stage).Dur("latency", time.Since(start)).AnErr("err", err).Msg("query hook invoked")
+}
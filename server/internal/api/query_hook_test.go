@@ -0,0 +1,170 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+type stubHook struct {
+	name string
+
+	beforeQuery string
+	beforeErr   error
+	beforeCalls int
+
+	afterHits  []model.SearchHit
+	afterErr   error
+	afterCalls int
+}
+
+func (s *stubHook) Name() string { return s.name }
+
+func (s *stubHook) BeforeEmbed(ctx context.Context, req *SearchRequest) (*SearchRequest, *model.Filter, error) {
+	s.beforeCalls++
+	if s.beforeErr != nil {
+		return nil, nil, s.beforeErr
+	}
+	if s.beforeQuery == "" {
+		return nil, nil, ErrSkip
+	}
+	rewritten := *req
+	rewritten.Query = s.beforeQuery
+	return &rewritten, nil, nil
+}
+
+func (s *stubHook) AfterSearch(ctx context.Context, req *SearchRequest, hits []model.SearchHit, ctxHits []model.ContextHit) ([]model.SearchHit, []model.ContextHit, error) {
+	s.afterCalls++
+	if s.afterErr != nil {
+		return nil, nil, s.afterErr
+	}
+	if s.afterHits == nil {
+		return nil, nil, ErrSkip
+	}
+	return s.afterHits, nil, nil
+}
+
+func TestHookChain_BeforeEmbed_RewritesQuery(t *testing.T) {
+	chain := NewHookChain(0, &stubHook{name: "rewriter", beforeQuery: "expanded query"})
+	req := &SearchRequest{Query: "original"}
+
+	out, err := chain.BeforeEmbed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Query != "expanded query" {
+		t.Fatalf("expected rewritten query, got %q", out.Query)
+	}
+}
+
+func TestHookChain_BeforeEmbed_SkipLeavesRequestUnchanged(t *testing.T) {
+	chain := NewHookChain(0, &stubHook{name: "noop"})
+	req := &SearchRequest{Query: "original"}
+
+	out, err := chain.BeforeEmbed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Query != "original" {
+		t.Fatalf("expected unchanged query on ErrSkip, got %q", out.Query)
+	}
+}
+
+func TestHookChain_BeforeEmbed_NonSkipErrorAborts(t *testing.T) {
+	chain := NewHookChain(0, &stubHook{name: "broken", beforeErr: errors.New("boom")})
+	req := &SearchRequest{Query: "original"}
+
+	if _, err := chain.BeforeEmbed(context.Background(), req); err == nil {
+		t.Fatalf("expected non-skip hook error to abort the chain")
+	}
+}
+
+func TestHookChain_AfterSearch_ReranksHits(t *testing.T) {
+	reranked := []model.SearchHit{{EntryID: "e2"}, {EntryID: "e1"}}
+	chain := NewHookChain(0, &stubHook{name: "reranker", afterHits: reranked})
+	req := &SearchRequest{Query: "q"}
+
+	hits, _, err := chain.AfterSearch(context.Background(), req, []model.SearchHit{{EntryID: "e1"}, {EntryID: "e2"}}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hits) != 2 || hits[0].EntryID != "e2" {
+		t.Fatalf("expected reranked hits, got %+v", hits)
+	}
+}
+
+func TestHookChain_AfterSearch_NonSkipErrorAborts(t *testing.T) {
+	chain := NewHookChain(0, &stubHook{name: "broken", afterErr: errors.New("boom")})
+	req := &SearchRequest{Query: "q"}
+
+	if _, _, err := chain.AfterSearch(context.Background(), req, []model.SearchHit{{EntryID: "e1"}}, nil); err == nil {
+		t.Fatalf("expected non-skip hook error to abort the chain")
+	}
+}
+
+func TestHyDEHook_EmbedsSynthesizedAnswerButKeepsRawQueryForBM25(t *testing.T) {
+	hook := NewHyDEHook(synthesizerFunc(func(ctx context.Context, query string) (string, error) {
+		return "a hypothetical answer about " + query, nil
+	}))
+	req := &SearchRequest{Query: "what is mycelian"}
+
+	rewritten, extraFilters, err := hook.BeforeEmbed(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if extraFilters != nil {
+		t.Fatalf("expected no extra filters from HyDEHook")
+	}
+	if rewritten.Query != "what is mycelian" {
+		t.Fatalf("expected Query to remain the raw query for BM25, got %q", rewritten.Query)
+	}
+	if rewritten.EmbedText() != "a hypothetical answer about what is mycelian" {
+		t.Fatalf("expected EmbedText to be the synthesized answer, got %q", rewritten.EmbedText())
+	}
+}
+
+func TestHyDEHook_SynthesizeErrorSurfaces(t *testing.T) {
+	hook := NewHyDEHook(synthesizerFunc(func(ctx context.Context, query string) (string, error) {
+		return "", errors.New("llm unavailable")
+	}))
+	if _, _, err := hook.BeforeEmbed(context.Background(), &SearchRequest{Query: "q"}); err == nil {
+		t.Fatalf("expected synthesizer error to surface")
+	}
+}
+
+type synthesizerFunc func(ctx context.Context, query string) (string, error)
+
+func (f synthesizerFunc) Synthesize(ctx context.Context, query string) (string, error) {
+	return f(ctx, query)
+}
+
+func TestHandleSearch_HookChainRunsBeforeEmbedAndAfterSearch(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	before := &stubHook{name: "noop"}
+	after := &stubHook{name: "reranker", afterHits: []model.SearchHit{{EntryID: "reranked"}}}
+	h.SetHookChain(NewHookChain(0, before, after))
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":2,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if before.beforeCalls != 1 {
+		t.Fatalf("expected BeforeEmbed to run once, got %d calls", before.beforeCalls)
+	}
+	if after.afterCalls != 1 {
+		t.Fatalf("expected AfterSearch to run once, got %d calls", after.afterCalls)
+	}
+}
@@ -3,39 +3,136 @@ package api
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-
 	"strings"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
 )
 
 // SearchRequest represents the payload for POST /api/search
 //
 // Fields:
 //
-//	memoryId – required, non-empty string
+//	memoryId – required unless vaultId is set; either a single memory UUID or
+//	           an array of memory UUIDs to search across (federated search)
+//	vaultId – optional, search every memory in this vault the caller can access
 //	query – required, non-empty string
 //	top_ke – optional, entries top-k (default: 5, range: 0-25)
 //	top_kc – optional, context shards top-k (default: 2, range: 1-10)
 //	include_raw_entries – optional, whether to include raw entries in response (default: false)
+//	fusion – optional, ranked-list fusion mode: "rrf", "weighted", or "none" (default: "none")
+//	alpha – optional (range: 0-1). When set, it replaces the handler's configured
+//	        semantic weight for hybrid Search/SearchContexts scoring. "weighted"
+//	        fusion uses it (default: 0.5) as the weight of the min-max normalised
+//	        entry scores, with 1-alpha on the context shard scores; it does not
+//	        blend separate semantic and lexical scores.
+//	k – optional, RRF rank-constant (default: 60)
+//	include_contexts_in_fusion – optional, fold context shards into the fused list (default: false)
+//	diversify – optional, apply MMR diversification to both entry and
+//	            context shard candidates (default: false); federated searches
+//	            diversify context shards only
+//	lambda – optional, MMR relevance/diversity trade-off (range: 0-1, default: 0.5)
+//	pool_size – optional, shared MMR candidate pool size for both entries and
+//	            context shards (default: max(3*top_kc, 20))
+//	explain – optional, attach a score breakdown (model.Explanation) to each
+//	          result (default: false, omitted entirely when false)
+//	bm25_boost – optional, multiplier applied to the lexical (BM25) component
+//	             of hybrid scoring before it is blended by alpha (range: >0, default: 1.0)
+//	vector_boost – optional, multiplier applied to the semantic (vector)
+//	               component of hybrid scoring before it is blended by alpha
+//	               (range: >0, default: 1.0)
+//	variant – optional, name of a configured SearchRouter variant to force
+//	          this request onto, bypassing deterministic shadow routing
+//	          (default: "", meaning the primary index)
 //
 // Validation is done via the Validate method.
 // User identification comes from API key authorization.
-//
-// This DTO is intentionally small; future versions may add filters.
 type SearchRequest struct {
-	MemoryID          string `json:"memoryId"`
-	Query             string `json:"query"`
-	TopKE             *int   `json:"top_ke,omitempty"`
-	TopKC             *int   `json:"top_kc,omitempty"`
-	IncludeRawEntries bool   `json:"include_raw_entries,omitempty"`
+	MemoryID                string        `json:"-"`
+	MemoryIDs               []string      `json:"-"`
+	VaultID                 string        `json:"vaultId,omitempty"`
+	Query                   string        `json:"query"`
+	TopKE                   *int          `json:"top_ke,omitempty"`
+	TopKC                   *int          `json:"top_kc,omitempty"`
+	IncludeRawEntries       bool          `json:"include_raw_entries,omitempty"`
+	Fusion                  FusionMode    `json:"fusion,omitempty"`
+	Alpha                   *float64      `json:"alpha,omitempty"`
+	K                       *int          `json:"k,omitempty"`
+	IncludeContextsInFusion bool          `json:"include_contexts_in_fusion,omitempty"`
+	Filter                  *model.Filter `json:"filter,omitempty"`
+	Diversify               bool          `json:"diversify,omitempty"`
+	Lambda                  *float64      `json:"lambda,omitempty"`
+	PoolSize                *int          `json:"pool_size,omitempty"`
+	Explain                 bool          `json:"explain,omitempty"`
+	BM25Boost               *float64      `json:"bm25_boost,omitempty"`
+	VectorBoost             *float64      `json:"vector_boost,omitempty"`
+	Variant                 string        `json:"variant,omitempty"`
+
+	// alphaProvided records whether the caller set alpha explicitly, before
+	// Validate applies its default. SearchHandler uses it to fall back to
+	// the handler's configured alpha instead of the weighted-fusion default
+	// when alpha is only being used to drive hybrid Search/SearchContexts.
+	alphaProvided bool
+
+	// embedText is the text actually embedded for vector search, if a
+	// QueryHook.BeforeEmbed sets it (e.g. HyDEHook substituting a
+	// synthesized hypothetical answer). Query remains the raw text used for
+	// the BM25 side of hybrid scoring regardless of embedText.
+	embedText string
+}
+
+// EmbedText returns the text that should be embedded: embedText if a
+// QueryHook has set one, otherwise Query.
+func (r *SearchRequest) EmbedText() string {
+	if r.embedText != "" {
+		return r.embedText
+	}
+	return r.Query
+}
+
+// SetEmbedText overrides the text embedded for vector search without
+// changing Query, so BM25 lexical scoring still matches the caller's
+// original input. Hooks that want their rewrite to affect BM25 too should
+// mutate Query directly instead.
+func (r *SearchRequest) SetEmbedText(text string) {
+	r.embedText = text
+}
+
+// UnmarshalJSON accepts memoryId as either a single string (existing
+// behavior) or an array of strings (federated search across several
+// memories). vaultId search requests may omit memoryId entirely.
+func (r *SearchRequest) UnmarshalJSON(data []byte) error {
+	type alias SearchRequest
+	aux := struct {
+		MemoryID json.RawMessage `json:"memoryId"`
+		*alias
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if len(aux.MemoryID) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(aux.MemoryID, &single); err == nil {
+		r.MemoryID = single
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(aux.MemoryID, &multi); err != nil {
+		return errors.New("memoryId must be a string or an array of strings")
+	}
+	r.MemoryIDs = multi
+	return nil
 }
 
 // Validate sanitises the struct and applies defaults.
 func (r *SearchRequest) Validate() error {
 	r.Query = strings.TrimSpace(r.Query)
 
-	if r.MemoryID == "" {
-		return errors.New("memoryId is required")
+	if r.MemoryID == "" && len(r.MemoryIDs) == 0 && r.VaultID == "" {
+		return errors.New("memoryId or vaultId is required")
 	}
 	if r.Query == "" {
 		return errors.New("query cannot be empty")
@@ -59,6 +156,63 @@ func (r *SearchRequest) Validate() error {
 		return errors.New("top_kc must be between 1 and 10")
 	}
 
+	// Apply fusion defaults and validate
+	if r.Fusion == "" {
+		r.Fusion = FusionNone
+	}
+	switch r.Fusion {
+	case FusionNone, FusionRRF, FusionWeighted:
+	default:
+		return fmt.Errorf("fusion must be one of %q, %q, %q", FusionNone, FusionRRF, FusionWeighted)
+	}
+	if r.K == nil {
+		defaultK := 60
+		r.K = &defaultK
+	} else if *r.K <= 0 {
+		return errors.New("k must be a positive integer")
+	}
+	r.alphaProvided = r.Alpha != nil
+	if r.Alpha == nil {
+		defaultAlpha := 0.5
+		r.Alpha = &defaultAlpha
+	} else if *r.Alpha < 0.0 || *r.Alpha > 1.0 {
+		return errors.New("alpha must be between 0.0 and 1.0")
+	}
+
+	if err := r.Filter.Validate(); err != nil {
+		return fmt.Errorf("filter: %w", err)
+	}
+
+	// Apply diversification defaults and validate
+	if r.Lambda == nil {
+		defaultLambda := 0.5
+		r.Lambda = &defaultLambda
+	} else if *r.Lambda < 0.0 || *r.Lambda > 1.0 {
+		return errors.New("lambda must be between 0.0 and 1.0")
+	}
+	if r.PoolSize == nil {
+		defaultPoolSize := 3 * *r.TopKC
+		if defaultPoolSize < 20 {
+			defaultPoolSize = 20
+		}
+		r.PoolSize = &defaultPoolSize
+	} else if *r.PoolSize < *r.TopKC {
+		return errors.New("pool_size must be at least top_kc")
+	}
+
+	if r.BM25Boost == nil {
+		defaultBM25Boost := 1.0
+		r.BM25Boost = &defaultBM25Boost
+	} else if *r.BM25Boost <= 0 {
+		return errors.New("bm25_boost must be greater than 0")
+	}
+	if r.VectorBoost == nil {
+		defaultVectorBoost := 1.0
+		r.VectorBoost = &defaultVectorBoost
+	} else if *r.VectorBoost <= 0 {
+		return errors.New("vector_boost must be greater than 0")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,191 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// rrfK is the rank constant used to merge per-memory result streams in a
+// federated search. It is independent of SearchRequest.K, which tunes the
+// entry/context fusion described in fuseResults.
+const rrfK = 60.0
+
+// PerMemorySummary summarizes a federated search's contribution from a
+// single memory.
+type PerMemorySummary struct {
+	EntryCount             int        `json:"entryCount"`
+	ContextCount           int        `json:"contextCount"`
+	LatestContextTimestamp *time.Time `json:"latestContextTimestamp,omitempty"`
+}
+
+// requestContext bundles the per-request values federatedSearch needs so its
+// signature doesn't grow with every new knob SearchRequest gains.
+type requestContext struct {
+	ctx     context.Context
+	apiKey  string
+	actorID string
+	req     *SearchRequest
+	vec     []float32
+}
+
+// memorySubresult is one memory's contribution to a federated search.
+type memorySubresult struct {
+	memoryID string
+	hits     []model.SearchHit
+	ctxHits  []model.ContextHit
+}
+
+// resolveMemoryIDs expands a SearchRequest into the concrete memory IDs to
+// search: VaultID (every memory in the vault the actor can access), an
+// explicit MemoryIDs list, or the single legacy MemoryID.
+func (sh *SearchHandler) resolveMemoryIDs(ctx context.Context, actorID string, req *SearchRequest) ([]string, error) {
+	if req.VaultID != "" {
+		ids, err := sh.idx.ListMemoriesByVault(ctx, actorID, req.VaultID)
+		if err != nil {
+			return nil, fmt.Errorf("listing memories for vault %s: %w", req.VaultID, err)
+		}
+		return ids, nil
+	}
+	if len(req.MemoryIDs) > 0 {
+		return req.MemoryIDs, nil
+	}
+	return []string{req.MemoryID}, nil
+}
+
+// federatedSearch runs entry and context search against each memory ID
+// independently, enforcing authorization per memory, then merges the
+// per-memory rankings into one global list via Reciprocal Rank Fusion so the
+// top-k budget is global rather than per-memory. latestContext/
+// latestContextTimestamp are the true most-recent context across all
+// memories (each fetched via idx.LatestContext, not inferred from
+// relevance-ranked SearchContexts results). partial is true if any memory's
+// subquery was skipped due to an authorization or backend error.
+func (sh *SearchHandler) federatedSearch(rc *requestContext, memoryIDs []string) (hits []model.SearchHit, ctxHits []model.ContextHit, perMemory map[string]PerMemorySummary, latestContext string, latestContextTimestamp time.Time, partial bool) {
+	perMemory = make(map[string]PerMemorySummary, len(memoryIDs))
+	results := make([]memorySubresult, 0, len(memoryIDs))
+
+	for _, memoryID := range memoryIDs {
+		if _, err := sh.authorizer.Authorize(rc.ctx, rc.apiKey, "memory.search", memoryID); err != nil {
+			log.Warn().Err(err).Str("memoryId", memoryID).Msg("federated search: skipping unauthorized memory")
+			partial = true
+			continue
+		}
+
+		alpha := sh.alpha
+		if rc.req.alphaProvided {
+			alpha = float32(*rc.req.Alpha)
+		}
+		bm25Boost := float32(*rc.req.BM25Boost)
+		vectorBoost := float32(*rc.req.VectorBoost)
+
+		var memHits []model.SearchHit
+		if *rc.req.TopKE > 0 {
+			var err error
+			// Federated entry hits are not MMR-diversified (only the merged
+			// context shards are, by HandleSearch), so entry search never
+			// asks the backend for vectors.
+			memHits, err = sh.idx.Search(rc.ctx, rc.actorID, memoryID, rc.req.Query, rc.vec, searchindex.SearchOptions{
+				TopK:              *rc.req.TopKE,
+				Alpha:             alpha,
+				IncludeRawEntries: rc.req.IncludeRawEntries,
+				Filter:            rc.req.Filter,
+				Explain:           rc.req.Explain,
+				BM25Boost:         bm25Boost,
+				VectorBoost:       vectorBoost,
+			})
+			if err != nil {
+				log.Error().Err(err).Str("memoryId", memoryID).Msg("federated search: entry subquery failed")
+				partial = true
+				continue
+			}
+		}
+
+		fetchKC := *rc.req.TopKC
+		if rc.req.Diversify {
+			fetchKC = *rc.req.PoolSize
+		}
+		memCtxHits, err := sh.idx.SearchContexts(rc.ctx, rc.actorID, memoryID, rc.req.Query, rc.vec, searchindex.SearchContextsOptions{
+			TopK:        fetchKC,
+			Alpha:       alpha,
+			Filter:      rc.req.Filter,
+			Explain:     rc.req.Explain,
+			BM25Boost:   bm25Boost,
+			VectorBoost: vectorBoost,
+		})
+		if err != nil {
+			log.Error().Err(err).Str("memoryId", memoryID).Msg("federated search: context subquery failed")
+			partial = true
+			continue
+		}
+
+		for i := range memHits {
+			memHits[i].MemoryID = memoryID
+		}
+		for i := range memCtxHits {
+			memCtxHits[i].MemoryID = memoryID
+		}
+
+		summary := PerMemorySummary{EntryCount: len(memHits), ContextCount: len(memCtxHits)}
+		memLatestCtx, memLatestTs, err := sh.idx.LatestContext(rc.ctx, rc.actorID, memoryID)
+		if err != nil {
+			log.Error().Err(err).Str("memoryId", memoryID).Msg("federated search: latest context fetch failed")
+			partial = true
+		} else {
+			summary.LatestContextTimestamp = &memLatestTs
+			if memLatestTs.After(latestContextTimestamp) {
+				latestContextTimestamp = memLatestTs
+				latestContext = memLatestCtx
+			}
+		}
+		perMemory[memoryID] = summary
+
+		results = append(results, memorySubresult{memoryID: memoryID, hits: memHits, ctxHits: memCtxHits})
+	}
+
+	return mergeHitsByRRF(results), mergeContextsByRRF(results), perMemory, latestContext, latestContextTimestamp, partial
+}
+
+func mergeHitsByRRF(results []memorySubresult) []model.SearchHit {
+	type scored struct {
+		hit   model.SearchHit
+		score float64
+	}
+	var all []scored
+	for _, r := range results {
+		for rank, hit := range r.hits {
+			all = append(all, scored{hit: hit, score: 1.0 / (rrfK + float64(rank+1))})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+	out := make([]model.SearchHit, len(all))
+	for i, s := range all {
+		out[i] = s.hit
+	}
+	return out
+}
+
+func mergeContextsByRRF(results []memorySubresult) []model.ContextHit {
+	type scored struct {
+		ctx   model.ContextHit
+		score float64
+	}
+	var all []scored
+	for _, r := range results {
+		for rank, ch := range r.ctxHits {
+			all = append(all, scored{ctx: ch, score: 1.0 / (rrfK + float64(rank+1))})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].score > all[j].score })
+	out := make([]model.ContextHit, len(all))
+	for i, s := range all {
+		out[i] = s.ctx
+	}
+	return out
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// fakeFederatedSearch embeds mockSearch but returns a distinct, per-memory
+// LatestContext, so federatedSearch's "true latest across memories" merge
+// (rather than inferring latest from SearchContexts's relevance-ranked
+// results) can be exercised.
+type fakeFederatedSearch struct {
+	*mockSearch
+	latest map[string]fakeLatestContext
+}
+
+type fakeLatestContext struct {
+	context string
+	ts      time.Time
+}
+
+func (f *fakeFederatedSearch) LatestContext(ctx context.Context, uid, mid string) (string, time.Time, error) {
+	v := f.latest[mid]
+	return v.context, v.ts, nil
+}
+
+func TestMergeHitsByRRF_InterleavesByRank(t *testing.T) {
+	results := []memorySubresult{
+		{memoryID: "m1", hits: []model.SearchHit{{EntryID: "a"}, {EntryID: "b"}}},
+		{memoryID: "m2", hits: []model.SearchHit{{EntryID: "c"}}},
+	}
+	merged := mergeHitsByRRF(results)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 merged hits, got %d", len(merged))
+	}
+	// Rank-1 hits from both memories (a, c) should outscore the rank-2 hit (b).
+	if merged[2].EntryID != "b" {
+		t.Fatalf("expected rank-2 hit to sort last, got %+v", merged)
+	}
+}
+
+func TestMergeContextsByRRF_InterleavesByRank(t *testing.T) {
+	results := []memorySubresult{
+		{memoryID: "m1", ctxHits: []model.ContextHit{{Context: "x"}, {Context: "y"}}},
+		{memoryID: "m2", ctxHits: []model.ContextHit{{Context: "z"}}},
+	}
+	merged := mergeContextsByRRF(results)
+	if len(merged) != 3 {
+		t.Fatalf("expected 3 merged contexts, got %d", len(merged))
+	}
+	if merged[2].Context != "y" {
+		t.Fatalf("expected rank-2 context to sort last, got %+v", merged)
+	}
+}
+
+func TestResolveMemoryIDs_VaultDelegatesToListMemoriesByVault(t *testing.T) {
+	srch := &mockSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	ids, err := h.resolveMemoryIDs(nil, "actor1", &SearchRequest{VaultID: "v1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ids != nil {
+		t.Fatalf("expected stub ListMemoriesByVault to return nil, got %v", ids)
+	}
+}
+
+func TestResolveMemoryIDs_ExplicitListPassesThrough(t *testing.T) {
+	srch := &mockSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	ids, err := h.resolveMemoryIDs(nil, "actor1", &SearchRequest{MemoryIDs: []string{"m1", "m2"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "m1" || ids[1] != "m2" {
+		t.Fatalf("expected [m1 m2], got %v", ids)
+	}
+}
+
+func TestResolveMemoryIDs_SingleLegacyMemoryID(t *testing.T) {
+	srch := &mockSearch{}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	ids, err := h.resolveMemoryIDs(nil, "actor1", &SearchRequest{MemoryID: "m1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "m1" {
+		t.Fatalf("expected [m1], got %v", ids)
+	}
+}
+
+func TestHandleSearch_FederatedAcrossMemoryIDs(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	body := bytes.NewBufferString(`{"memoryId":["m1","m2"],"query":"hi","top_ke":5,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Count     int                         `json:"count"`
+		PerMemory map[string]PerMemorySummary `json:"perMemory"`
+		Partial   bool                        `json:"partial"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.PerMemory) != 2 {
+		t.Fatalf("expected perMemory entries for both memories, got %v", resp.PerMemory)
+	}
+	if resp.Partial {
+		t.Fatalf("expected partial=false when both memories succeed")
+	}
+	if resp.Count == 0 {
+		t.Fatalf("expected merged entries across memories")
+	}
+}
+
+func TestHandleSearch_FederatedLatestContextIsTrueMaxAcrossMemories(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+	srch := &fakeFederatedSearch{
+		mockSearch: &mockSearch{},
+		latest: map[string]fakeLatestContext{
+			"m1": {context: "m1-latest", ts: older},
+			"m2": {context: "m2-latest", ts: newer},
+		},
+	}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":["m1","m2"],"query":"hi","top_ke":5,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		LatestContext string                      `json:"latestContext"`
+		PerMemory     map[string]PerMemorySummary `json:"perMemory"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.LatestContext != "m2-latest" {
+		t.Fatalf("expected top-level latestContext to be the true max across memories (m2-latest), got %q", resp.LatestContext)
+	}
+	if resp.PerMemory["m1"].LatestContextTimestamp == nil || !resp.PerMemory["m1"].LatestContextTimestamp.Equal(older) {
+		t.Fatalf("expected m1's per-memory latest context timestamp to come from its own idx.LatestContext call, got %+v", resp.PerMemory["m1"])
+	}
+}
@@ -0,0 +1,120 @@
+package api
+
+import (
+	"sort"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// FusionMode selects how entry and context shard rankings are combined into
+// a single list on SearchResponse.
+type FusionMode string
+
+const (
+	// FusionNone disables fusion; Fused is omitted from the response.
+	FusionNone FusionMode = "none"
+	// FusionRRF combines rankings via Reciprocal Rank Fusion.
+	FusionRRF FusionMode = "rrf"
+	// FusionWeighted combines min-max normalised scores with an alpha weight.
+	FusionWeighted FusionMode = "weighted"
+)
+
+// FusedHit is a single entry in the unified, cross-source ranked list.
+type FusedHit struct {
+	Kind        string           `json:"kind"` // "entry" or "context"
+	Score       float64          `json:"score"`
+	Entry       *model.SearchHit `json:"entry,omitempty"`
+	Context     map[string]any   `json:"context,omitempty"`
+	EntryRank   int              `json:"entryRank,omitempty"`
+	ContextRank int              `json:"contextRank,omitempty"`
+}
+
+// fuseResults merges the independently-ranked entry and context-shard lists
+// into a single ranked list per req.Fusion. Ties are broken by preferring the
+// entry over the context, then by original (stable) rank order.
+func fuseResults(req *SearchRequest, hits []model.SearchHit, contexts []map[string]any) []FusedHit {
+	if req.Fusion == FusionNone {
+		return nil
+	}
+
+	fused := make([]FusedHit, 0, len(hits)+len(contexts))
+
+	switch req.Fusion {
+	case FusionRRF:
+		k := float64(*req.K)
+		for i, h := range hits {
+			rank := i + 1
+			fused = append(fused, FusedHit{Kind: "entry", Score: 1.0 / (k + float64(rank)), Entry: &hits[i], EntryRank: rank})
+			_ = h
+		}
+		if req.IncludeContextsInFusion {
+			for i, c := range contexts {
+				rank := i + 1
+				fused = append(fused, FusedHit{Kind: "context", Score: 1.0 / (k + float64(rank)), Context: c, ContextRank: rank})
+			}
+		}
+	case FusionWeighted:
+		alpha := *req.Alpha
+		entryScores := normalize(extractEntryScores(hits))
+		for i := range hits {
+			fused = append(fused, FusedHit{Kind: "entry", Score: alpha * entryScores[i], Entry: &hits[i], EntryRank: i + 1})
+		}
+		if req.IncludeContextsInFusion {
+			ctxScores := normalize(extractContextScores(contexts))
+			for i, c := range contexts {
+				fused = append(fused, FusedHit{Kind: "context", Score: (1 - alpha) * ctxScores[i], Context: c, ContextRank: i + 1})
+			}
+		}
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool { return fused[i].Score > fused[j].Score })
+	return fused
+}
+
+func extractEntryScores(hits []model.SearchHit) []float64 {
+	out := make([]float64, len(hits))
+	for i, h := range hits {
+		out[i] = float64(h.Score)
+	}
+	return out
+}
+
+func extractContextScores(contexts []map[string]any) []float64 {
+	out := make([]float64, len(contexts))
+	for i, c := range contexts {
+		switch s := c["score"].(type) {
+		case float64:
+			out[i] = s
+		case float32:
+			out[i] = float64(s)
+		}
+	}
+	return out
+}
+
+// normalize min-max scales scores to [0,1]. A constant input maps to all 1s.
+func normalize(scores []float64) []float64 {
+	out := make([]float64, len(scores))
+	if len(scores) == 0 {
+		return out
+	}
+	min, max := scores[0], scores[0]
+	for _, s := range scores {
+		if s < min {
+			min = s
+		}
+		if s > max {
+			max = s
+		}
+	}
+	if max == min {
+		for i := range out {
+			out[i] = 1
+		}
+		return out
+	}
+	for i, s := range scores {
+		out[i] = (s - min) / (max - min)
+	}
+	return out
+}
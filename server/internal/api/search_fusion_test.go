@@ -0,0 +1,113 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func kPtr(v int) *int             { return &v }
+func alphaPtr(v float64) *float64 { return &v }
+
+func TestFuseResults_RRFRecurrence(t *testing.T) {
+	hits := []model.SearchHit{
+		{EntryID: "e1", Score: 0.9},
+		{EntryID: "e2", Score: 0.5},
+	}
+	contexts := []map[string]any{
+		{"context": "c1", "score": 0.8},
+	}
+	req := &SearchRequest{Fusion: FusionRRF, K: kPtr(60), IncludeContextsInFusion: true}
+
+	fused := fuseResults(req, hits, contexts)
+	if len(fused) != 3 {
+		t.Fatalf("expected 3 fused hits, got %d", len(fused))
+	}
+
+	want := 1.0 / (60.0 + 1.0)
+	if fused[0].Score != want {
+		t.Fatalf("expected top score %v, got %v", want, fused[0].Score)
+	}
+	if fused[0].Kind != "entry" || fused[0].Entry.EntryID != "e1" {
+		t.Fatalf("expected e1 to rank first, got %+v", fused[0])
+	}
+}
+
+func TestFuseResults_ExcludesContextsByDefault(t *testing.T) {
+	hits := []model.SearchHit{{EntryID: "e1", Score: 0.9}}
+	contexts := []map[string]any{{"context": "c1", "score": 0.95}}
+	req := &SearchRequest{Fusion: FusionRRF, K: kPtr(60)}
+
+	fused := fuseResults(req, hits, contexts)
+	if len(fused) != 1 {
+		t.Fatalf("expected contexts excluded from fusion, got %d hits", len(fused))
+	}
+	if fused[0].Kind != "entry" {
+		t.Fatalf("expected only the entry to be fused, got %+v", fused[0])
+	}
+}
+
+func TestFuseResults_NoneReturnsNil(t *testing.T) {
+	req := &SearchRequest{Fusion: FusionNone}
+	if fused := fuseResults(req, nil, nil); fused != nil {
+		t.Fatalf("expected nil for FusionNone, got %+v", fused)
+	}
+}
+
+func TestFuseResults_WeightedTieBreak(t *testing.T) {
+	hits := []model.SearchHit{
+		{EntryID: "e1", Score: 1.0},
+		{EntryID: "e2", Score: 1.0},
+	}
+	req := &SearchRequest{Fusion: FusionWeighted, Alpha: alphaPtr(0.5)}
+
+	fused := fuseResults(req, hits, nil)
+	if len(fused) != 2 {
+		t.Fatalf("expected 2 fused hits, got %d", len(fused))
+	}
+	// Equal normalized scores map to a constant 1.0; original order is preserved
+	// for ties since sort.SliceStable is used.
+	if fused[0].Entry.EntryID != "e1" || fused[1].Entry.EntryID != "e2" {
+		t.Fatalf("expected stable tie-break order e1,e2, got %s,%s", fused[0].Entry.EntryID, fused[1].Entry.EntryID)
+	}
+}
+
+func TestFuseResults_WeightedPreservesDistinctContextScores(t *testing.T) {
+	// Build contexts the same way search_handler.go does: from
+	// model.ContextHit.Score (a float32) via an explicit float64
+	// conversion. A c["score"].(float64) type assertion that instead fails
+	// silently on a stored float32 would make every score default to the
+	// zero value, and normalize()'s max==min branch would then collapse
+	// every context to 1.0.
+	ctxHits := []model.ContextHit{
+		{Context: "low", Score: 0.2},
+		{Context: "high", Score: 0.9},
+	}
+	contexts := make([]map[string]any, len(ctxHits))
+	for i, ch := range ctxHits {
+		contexts[i] = map[string]any{"context": ch.Context, "score": float64(ch.Score)}
+	}
+	hits := []model.SearchHit{{EntryID: "e1", Score: 1.0}}
+	req := &SearchRequest{Fusion: FusionWeighted, Alpha: alphaPtr(0.5), IncludeContextsInFusion: true}
+
+	fused := fuseResults(req, hits, contexts)
+
+	var lowScore, highScore float64
+	for _, f := range fused {
+		if f.Kind != "context" {
+			continue
+		}
+		switch f.Context["context"] {
+		case "low":
+			lowScore = f.Score
+		case "high":
+			highScore = f.Score
+		}
+	}
+	if lowScore == highScore {
+		t.Fatalf("expected distinct context scores to survive fusion, both collapsed to %v", lowScore)
+	}
+	if highScore <= lowScore {
+		t.Fatalf("expected high-scoring context to rank above low-scoring context, got high=%v low=%v", highScore, lowScore)
+	}
+}
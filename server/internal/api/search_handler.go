@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -12,6 +13,7 @@ import (
 	emb "github.com/mycelian/mycelian-memory/server/internal/embeddings"
 	"github.com/mycelian/mycelian-memory/server/internal/model"
 	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex/fuzzy"
 )
 
 // SearchHandler handles POST /api/search using native searchindex and embeddings.
@@ -20,8 +22,18 @@ type SearchHandler struct {
 	idx        searchindex.Index
 	alpha      float32
 	authorizer auth.Authorizer
+	router     *SearchRouter
+	hooks      *HookChain
+
+	fuzzyCfg     FuzzyConfig
+	fuzzySources map[string]fuzzy.Source
 }
 
+// NewSearchHandler wires the two calls HandleSearch always makes (emb,
+// idx) plus the default hybrid-scoring alpha. Everything optional —
+// SearchRouter, FuzzyConfig, the HookChain — is attached afterwards via its
+// own Set* method instead of growing this constructor's parameter list; see
+// SetRouter, SetFuzzyConfig, and SetHookChain for why.
 func NewSearchHandler(emb emb.EmbeddingProvider, idx searchindex.Index, alpha float32, authorizer auth.Authorizer) (*SearchHandler, error) {
 	if alpha < 0.0 || alpha > 1.0 {
 		return nil, fmt.Errorf("alpha parameter must be in the range [0.0, 1.0], got %f", alpha)
@@ -29,6 +41,39 @@ func NewSearchHandler(emb emb.EmbeddingProvider, idx searchindex.Index, alpha fl
 	return &SearchHandler{emb: emb, idx: idx, alpha: alpha, authorizer: authorizer}, nil
 }
 
+// SetRouter attaches a SearchRouter used to resolve an explicit
+// SearchRequest.Variant and to shadow-compare a deterministic slice of
+// traffic against configured variants. A nil router (the default) disables
+// both behaviors and HandleSearch uses idx directly.
+func (h *SearchHandler) SetRouter(router *SearchRouter) {
+	h.router = router
+}
+
+// SetFuzzyConfig attaches the fuzzy cross-scope search config and its
+// per-context-type candidate sources. Until this is called, FuzzyConfig's
+// zero value leaves Enabled false and HandleFuzzySearch responds 503.
+//
+// This is deliberately a post-construction setter rather than a
+// NewSearchHandler parameter, matching SetRouter and SetHookChain: all
+// three wire server-owned config that's assembled after the handler's
+// required collaborators (emb, idx, authorizer) are known, and adding them
+// to the constructor would mean editing every existing NewSearchHandler
+// call site each time the server grows another optional feature. The
+// tradeoff is real — a handler is reachable with fuzzy search disabled
+// between construction and this call — so callers must invoke it before
+// serving traffic if fuzzy search should be live from the start.
+func (h *SearchHandler) SetFuzzyConfig(cfg FuzzyConfig, sources map[string]fuzzy.Source) {
+	h.fuzzyCfg = cfg
+	h.fuzzySources = sources
+}
+
+// SetHookChain attaches a HookChain run around h.emb.Embed and the
+// Search/SearchContexts calls. A nil chain (the default) disables hook
+// processing entirely.
+func (h *SearchHandler) SetHookChain(chain *HookChain) {
+	h.hooks = chain
+}
+
 func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	// Extract API key from Authorization header
 	apiKey, err := auth.ExtractAPIKey(r)
@@ -54,9 +99,26 @@ func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Info().Str("memoryId", req.MemoryID).Str("query", req.Query).Int("top_ke", *req.TopKE).Int("top_kc", *req.TopKC).Str("actorId", actorInfo.ActorID).Msg("search request received")
+	if h.hooks != nil {
+		req, err = h.hooks.BeforeEmbed(r.Context(), req)
+		if err != nil {
+			log.Error().Err(err).Msg("query hook failed")
+			respond.WriteError(w, http.StatusInternalServerError, "query hook failed")
+			return
+		}
+	}
 
-	vec, err := h.emb.Embed(r.Context(), req.Query)
+	memoryIDs, err := h.resolveMemoryIDs(r.Context(), actorInfo.ActorID, req)
+	if err != nil {
+		log.Error().Err(err).Str("vaultId", req.VaultID).Msg("resolving memory IDs failed")
+		respond.WriteError(w, http.StatusInternalServerError, "resolving memories unavailable")
+		return
+	}
+	federated := len(memoryIDs) != 1 || req.VaultID != "" || len(req.MemoryIDs) > 0
+
+	log.Info().Strs("memoryIds", memoryIDs).Str("query", req.Query).Int("top_ke", *req.TopKE).Int("top_kc", *req.TopKC).Str("actorId", actorInfo.ActorID).Msg("search request received")
+
+	vec, err := h.emb.Embed(r.Context(), req.EmbedText())
 	if err != nil {
 		log.Error().Err(err).Str("query", req.Query).Msg("embedding failed")
 		respond.WriteError(w, http.StatusInternalServerError, "embedding service unavailable")
@@ -64,42 +126,153 @@ func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug().Int("vectorLength", len(vec)).Msg("embedding generated")
 
-	// Search for entries if top_ke > 0
 	var hits []model.SearchHit
-	if *req.TopKE > 0 {
-		hits, err = h.idx.Search(r.Context(), actorInfo.ActorID, req.MemoryID, req.Query, vec, *req.TopKE, h.alpha, req.IncludeRawEntries)
+	var ctxHits []model.ContextHit
+	var perMemory map[string]PerMemorySummary
+	var partial bool
+	var latestCtx string
+	var latestTs time.Time
+
+	if federated {
+		rc := &requestContext{ctx: r.Context(), apiKey: apiKey, actorID: actorInfo.ActorID, req: req, vec: vec}
+		hits, ctxHits, perMemory, latestCtx, latestTs, partial = h.federatedSearch(rc, memoryIDs)
+		if len(hits) > *req.TopKE {
+			hits = hits[:*req.TopKE]
+		}
+	} else {
+		memoryID := memoryIDs[0]
+
+		alpha := h.alpha
+		if req.alphaProvided {
+			alpha = float32(*req.Alpha)
+		}
+		bm25Boost := float32(*req.BM25Boost)
+		vectorBoost := float32(*req.VectorBoost)
+
+		idx := h.idx
+		if h.router != nil {
+			idx = h.router.Resolve(req.Variant)
+		}
+
+		// Search for entries if top_ke > 0. When diversification is
+		// requested, over-fetch a candidate pool (with vectors, so MMR can
+		// measure inter-candidate redundancy) and re-rank it below before
+		// truncating to top_ke.
+		fetchKE := *req.TopKE
+		if req.Diversify && fetchKE > 0 {
+			fetchKE = *req.PoolSize
+		}
+		if *req.TopKE > 0 {
+			hits, err = idx.Search(r.Context(), actorInfo.ActorID, memoryID, req.Query, vec, searchindex.SearchOptions{
+				TopK:              fetchKE,
+				Alpha:             alpha,
+				IncludeRawEntries: req.IncludeRawEntries,
+				Filter:            req.Filter,
+				Explain:           req.Explain,
+				BM25Boost:         bm25Boost,
+				VectorBoost:       vectorBoost,
+				IncludeVectors:    req.Diversify,
+			})
+			if err != nil {
+				log.Error().Err(err).Str("memoryId", memoryID).Str("query", req.Query).Msg("search failed")
+				respond.WriteError(w, http.StatusInternalServerError, "search service unavailable")
+				return
+			}
+			if req.Diversify && len(hits) > *req.TopKE {
+				hits = diversifyHits(hits, *req.TopKE, *req.Lambda)
+			}
+		}
+		log.Info().Int("hitCount", len(hits)).Str("memoryId", memoryID).Msg("search completed")
+
+		// Shadow-compare against a configured variant in the background.
+		// Only when the caller didn't already force a specific variant —
+		// an explicit request is already being served by that variant, so
+		// there is nothing left to compare it against.
+		if h.router != nil && req.Variant == "" {
+			go h.router.ShadowCompare(context.Background(), actorInfo.ActorID, memoryID, req.Query, vec, *req.TopKE, alpha, req.Filter, hits)
+		}
+
+		// Always fetch latest context
+		latestCtx, latestTs, err = idx.LatestContext(r.Context(), actorInfo.ActorID, memoryID)
+		if err != nil {
+			log.Error().Err(err).Str("memoryId", memoryID).Msg("latest context fetch failed")
+			respond.WriteError(w, http.StatusInternalServerError, "latest context unavailable")
+			return
+		}
+
+		// Search for context shards (always, since minimum is 1). When
+		// diversification is requested, over-fetch a candidate pool and
+		// re-rank it with MMR before truncating to top_kc.
+		fetchKC := *req.TopKC
+		if req.Diversify {
+			fetchKC = *req.PoolSize
+		}
+		ctxHits, err = idx.SearchContexts(r.Context(), actorInfo.ActorID, memoryID, req.Query, vec, searchindex.SearchContextsOptions{
+			TopK:        fetchKC,
+			Alpha:       alpha,
+			Filter:      req.Filter,
+			Explain:     req.Explain,
+			BM25Boost:   bm25Boost,
+			VectorBoost: vectorBoost,
+		})
 		if err != nil {
-			log.Error().Err(err).Str("memoryId", req.MemoryID).Str("query", req.Query).Msg("search failed")
-			respond.WriteError(w, http.StatusInternalServerError, "search service unavailable")
+			log.Error().Err(err).Str("memoryId", memoryID).Msg("context search failed")
+			respond.WriteError(w, http.StatusInternalServerError, "context search unavailable")
 			return
 		}
 	}
-	log.Info().Int("hitCount", len(hits)).Str("memoryId", req.MemoryID).Msg("search completed")
 
-	// Always fetch latest context
-	latestCtx, latestTs, err := h.idx.LatestContext(r.Context(), actorInfo.ActorID, req.MemoryID)
-	if err != nil {
-		log.Error().Err(err).Str("memoryId", req.MemoryID).Msg("latest context fetch failed")
-		respond.WriteError(w, http.StatusInternalServerError, "latest context unavailable")
-		return
+	if h.hooks != nil {
+		hits, ctxHits, err = h.hooks.AfterSearch(r.Context(), req, hits, ctxHits)
+		if err != nil {
+			log.Error().Err(err).Msg("query hook failed")
+			respond.WriteError(w, http.StatusInternalServerError, "query hook failed")
+			return
+		}
 	}
 
-	// Search for context shards (always, since minimum is 1)
-	ctxHits, err := h.idx.SearchContexts(r.Context(), actorInfo.ActorID, req.MemoryID, req.Query, vec, *req.TopKC, h.alpha)
-	if err != nil {
-		log.Error().Err(err).Str("memoryId", req.MemoryID).Msg("context search failed")
-		respond.WriteError(w, http.StatusInternalServerError, "context search unavailable")
-		return
+	preDiversificationRank := make([]int, len(ctxHits))
+	for i := range ctxHits {
+		preDiversificationRank[i] = i + 1
+	}
+	if req.Diversify && len(ctxHits) > *req.TopKC {
+		candidates := make([]mmrCandidate, len(ctxHits))
+		for i, ch := range ctxHits {
+			candidates[i] = mmrCandidate{Score: float64(ch.Score), Vector: ch.Vector}
+		}
+		selected := mmrSelect(candidates, *req.TopKC, *req.Lambda)
+		reordered := make([]model.ContextHit, len(selected))
+		reorderedRank := make([]int, len(selected))
+		for i, idx := range selected {
+			reordered[i] = ctxHits[idx]
+			reorderedRank[i] = preDiversificationRank[idx]
+		}
+		ctxHits = reordered
+		preDiversificationRank = reorderedRank
+	} else if len(ctxHits) > *req.TopKC {
+		ctxHits = ctxHits[:*req.TopKC]
+		preDiversificationRank = preDiversificationRank[:*req.TopKC]
 	}
 
 	// Build contexts array
 	contexts := make([]map[string]any, 0, len(ctxHits))
-	for _, ch := range ctxHits {
-		contexts = append(contexts, map[string]any{
+	for i, ch := range ctxHits {
+		c := map[string]any{
 			"context":   ch.Context,
 			"timestamp": ch.Timestamp.Format(time.RFC3339),
-			"score":     ch.Score,
-		})
+			"score":     float64(ch.Score),
+			"rank":      i + 1,
+		}
+		if req.Diversify {
+			c["preDiversificationRank"] = preDiversificationRank[i]
+		}
+		if federated {
+			c["memoryId"] = ch.MemoryID
+		}
+		if req.Explain && ch.Explanation != nil {
+			c["explanation"] = ch.Explanation
+		}
+		contexts = append(contexts, c)
 	}
 
 	// Build response with consistent structure
@@ -111,5 +284,14 @@ func (h *SearchHandler) HandleSearch(w http.ResponseWriter, r *http.Request) {
 		"contexts":               contexts,
 	}
 
+	if federated {
+		resp["perMemory"] = perMemory
+		resp["partial"] = partial
+	}
+
+	if fused := fuseResults(req, hits, contexts); fused != nil {
+		resp["fused"] = fused
+	}
+
 	respond.WriteJSON(w, http.StatusOK, resp)
 }
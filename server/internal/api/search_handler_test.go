@@ -10,6 +10,7 @@ import (
 
 	"github.com/mycelian/mycelian-memory/server/internal/auth"
 	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 )
 
 type mockEmbedder struct {
@@ -24,24 +25,54 @@ func (m *mockEmbedder) Embed(ctx context.Context, text string) ([]float32, error
 type mockSearch struct {
 	calls int
 	empty bool
+
+	// lastAlpha/lastBM25Boost/lastVectorBoost record the most recent
+	// Search call's tuning parameters, for assertions on threading.
+	lastAlpha          float32
+	lastBM25Boost      float32
+	lastVectorBoost    float32
+	lastIncludeVectors bool
+
+	// hits, when non-nil, is returned verbatim by Search instead of the
+	// single canned "e1" hit, letting MMR-diversification tests control
+	// the candidate pool precisely.
+	hits []model.SearchHit
 }
 
-func (m *mockSearch) Search(ctx context.Context, uid, mid, q string, v []float32, kE int, a float32, includeRawEntries bool) ([]model.SearchHit, error) {
+func (m *mockSearch) Search(ctx context.Context, uid, mid, q string, v []float32, opts searchindex.SearchOptions) ([]model.SearchHit, error) {
 	m.calls++
+	m.lastAlpha = opts.Alpha
+	m.lastBM25Boost = opts.BM25Boost
+	m.lastVectorBoost = opts.VectorBoost
+	m.lastIncludeVectors = opts.IncludeVectors
 	if m.empty {
 		return []model.SearchHit{}, nil
 	}
-	return []model.SearchHit{{EntryID: "e1", Summary: "s", Score: 0.9, CreationTime: time.Now()}}, nil
+	if m.hits != nil {
+		return m.hits, nil
+	}
+	hit := model.SearchHit{EntryID: "e1", Summary: "s", Score: 0.9, CreationTime: time.Now()}
+	if opts.Explain {
+		hit.Explanation = &model.Explanation{SemanticScore: 0.9, LexicalScore: 0.0, FusionWeight: opts.Alpha, TopLexicalField: "summary"}
+	}
+	if opts.IncludeVectors {
+		hit.Vector = v
+	}
+	return []model.SearchHit{hit}, nil
 }
 
 func (m *mockSearch) LatestContext(ctx context.Context, uid, mid string) (string, time.Time, error) {
 	return "ctx", time.Now(), nil
 }
 
-func (m *mockSearch) SearchContexts(ctx context.Context, uid, mid, q string, v []float32, kC int, a float32) ([]model.ContextHit, error) {
-	out := make([]model.ContextHit, 0, kC)
-	for i := 0; i < kC; i++ {
-		out = append(out, model.ContextHit{Context: "ctx", Timestamp: time.Now(), Score: 0.8})
+func (m *mockSearch) SearchContexts(ctx context.Context, uid, mid, q string, v []float32, opts searchindex.SearchContextsOptions) ([]model.ContextHit, error) {
+	out := make([]model.ContextHit, 0, opts.TopK)
+	for i := 0; i < opts.TopK; i++ {
+		ch := model.ContextHit{Context: "ctx", Timestamp: time.Now(), Score: 0.8}
+		if opts.Explain {
+			ch.Explanation = &model.Explanation{SemanticScore: 0.8, LexicalScore: 0.0, FusionWeight: opts.Alpha}
+		}
+		out = append(out, ch)
 	}
 	return out, nil
 }
@@ -55,6 +86,10 @@ func (m *mockSearch) UpsertContext(ctx context.Context, ctxID string, vec []floa
 	return nil
 }
 
+func (m *mockSearch) ListMemoriesByVault(ctx context.Context, actorID, vaultID string) ([]string, error) {
+	return nil, nil
+}
+
 func (m *mockSearch) DeleteEntry(ctx context.Context, userID, entryID string) error     { return nil }
 func (m *mockSearch) DeleteContext(ctx context.Context, userID, contextID string) error { return nil }
 func (m *mockSearch) DeleteMemory(ctx context.Context, userID, memoryID string) error   { return nil }
@@ -161,6 +196,60 @@ func TestHandleSearch_ContextsArray_KCLimit(t *testing.T) {
 	}
 }
 
+func TestHandleSearch_ExplainOmittedByDefault(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hi","top_ke":5,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	var resp struct {
+		Entries  []model.SearchHit `json:"entries"`
+		Contexts []map[string]any  `json:"contexts"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Entries[0].Explanation != nil {
+		t.Fatalf("expected no explanation when explain=false, got %+v", resp.Entries[0].Explanation)
+	}
+	if _, ok := resp.Contexts[0]["explanation"]; ok {
+		t.Fatalf("expected no explanation key on contexts when explain=false")
+	}
+}
+
+func TestHandleSearch_ExplainIncludesScoreBreakdown(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hi","top_ke":5,"top_kc":1,"explain":true}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	var resp struct {
+		Entries  []model.SearchHit `json:"entries"`
+		Contexts []map[string]any  `json:"contexts"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Entries[0].Explanation == nil {
+		t.Fatalf("expected explanation when explain=true")
+	}
+	if _, ok := resp.Contexts[0]["explanation"]; !ok {
+		t.Fatalf("expected explanation key on contexts when explain=true")
+	}
+}
+
 func TestHandleSearch_NoResults(t *testing.T) {
 	emb := &mockEmbedder{}
 	srch := &mockSearch{empty: true}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"math"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+// mmrCandidate is the minimal shape needed to diversify a candidate pool via
+// Maximal Marginal Relevance: a relevance score against the query plus the
+// embedding vector used to measure inter-candidate redundancy.
+type mmrCandidate struct {
+	Score  float64
+	Vector []float32
+}
+
+// mmrSelect iteratively picks up to k indices from candidates, maximizing
+// lambda*sim(query,d) - (1-lambda)*max_{s in selected} sim(d,s) at each step,
+// where sim(query,d) is candidates[d].Score and sim(d,s) is cosine similarity
+// between candidate vectors. Returns the chosen indices in selection order
+// (most relevant/least redundant first); each index is also the candidate's
+// pre-diversification rank (index+1) in the original pool.
+func mmrSelect(candidates []mmrCandidate, k int, lambda float64) []int {
+	if k <= 0 || len(candidates) == 0 {
+		return nil
+	}
+	if k > len(candidates) {
+		k = len(candidates)
+	}
+
+	selected := make([]int, 0, k)
+	chosen := make(map[int]bool, k)
+	for len(selected) < k {
+		best := -1
+		var bestMMR float64
+		for i, c := range candidates {
+			if chosen[i] {
+				continue
+			}
+			var maxSim float64
+			for _, s := range selected {
+				if sim := cosineSimilarity(c.Vector, candidates[s].Vector); sim > maxSim {
+					maxSim = sim
+				}
+			}
+			mmr := lambda*c.Score - (1-lambda)*maxSim
+			if best == -1 || mmr > bestMMR {
+				best = i
+				bestMMR = mmr
+			}
+		}
+		selected = append(selected, best)
+		chosen[best] = true
+	}
+	return selected
+}
+
+// diversifyHits re-ranks an over-fetched entry candidate pool with MMR and
+// truncates it to k, using each hit's already-computed relevance Score and
+// its embedding Vector (populated by idx.Search when diversify is
+// requested) to measure redundancy.
+func diversifyHits(hits []model.SearchHit, k int, lambda float64) []model.SearchHit {
+	candidates := make([]mmrCandidate, len(hits))
+	for i, hit := range hits {
+		candidates[i] = mmrCandidate{Score: float64(hit.Score), Vector: hit.Vector}
+	}
+	selected := mmrSelect(candidates, k, lambda)
+	reordered := make([]model.SearchHit, len(selected))
+	for i, idx := range selected {
+		reordered[i] = hits[idx]
+	}
+	return reordered
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(b) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
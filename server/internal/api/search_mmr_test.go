@@ -0,0 +1,77 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestMMRSelect_DemotesRedundantNearDuplicates(t *testing.T) {
+	// d0 and d1 are near-identical (redundant); d2 is distinct but slightly
+	// less relevant. With lambda favoring diversity, d2 should be preferred
+	// over the near-duplicate d1 once d0 is selected.
+	candidates := []mmrCandidate{
+		{Score: 0.95, Vector: []float32{1, 0, 0}},    // d0: most relevant
+		{Score: 0.94, Vector: []float32{0.99, 0.01, 0}}, // d1: near-duplicate of d0
+		{Score: 0.80, Vector: []float32{0, 1, 0}},    // d2: distinct
+	}
+
+	selected := mmrSelect(candidates, 2, 0.5)
+	if len(selected) != 2 {
+		t.Fatalf("expected 2 selections, got %d", len(selected))
+	}
+	if selected[0] != 0 {
+		t.Fatalf("expected most relevant candidate first, got %d", selected[0])
+	}
+	if selected[1] != 2 {
+		t.Fatalf("expected distinct candidate d2 preferred over near-duplicate d1, got %d", selected[1])
+	}
+}
+
+func TestMMRSelect_LambdaOneIsPureRelevance(t *testing.T) {
+	candidates := []mmrCandidate{
+		{Score: 0.5, Vector: []float32{1, 0}},
+		{Score: 0.9, Vector: []float32{1, 0}},
+	}
+	selected := mmrSelect(candidates, 2, 1.0)
+	if !reflect.DeepEqual(selected, []int{1, 0}) {
+		t.Fatalf("expected pure relevance order [1,0], got %v", selected)
+	}
+}
+
+func TestMMRSelect_KLargerThanPool(t *testing.T) {
+	candidates := []mmrCandidate{{Score: 1, Vector: []float32{1}}}
+	selected := mmrSelect(candidates, 5, 0.5)
+	if len(selected) != 1 {
+		t.Fatalf("expected selection capped at pool size, got %d", len(selected))
+	}
+}
+
+func TestDiversifyHits_DemotesRedundantNearDuplicates(t *testing.T) {
+	hits := []model.SearchHit{
+		{EntryID: "d0", Score: 0.95, Vector: []float32{1, 0, 0}},
+		{EntryID: "d1", Score: 0.94, Vector: []float32{0.99, 0.01, 0}},
+		{EntryID: "d2", Score: 0.80, Vector: []float32{0, 1, 0}},
+	}
+
+	selected := diversifyHits(hits, 2, 0.5)
+	if len(selected) != 2 {
+		t.Fatalf("expected 2 selections, got %d", len(selected))
+	}
+	if selected[0].EntryID != "d0" || selected[1].EntryID != "d2" {
+		t.Fatalf("expected [d0, d2], got %+v", selected)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if sim := cosineSimilarity([]float32{1, 0}, []float32{1, 0}); sim != 1 {
+		t.Fatalf("expected identical vectors to have similarity 1, got %v", sim)
+	}
+	if sim := cosineSimilarity([]float32{1, 0}, []float32{0, 1}); sim != 0 {
+		t.Fatalf("expected orthogonal vectors to have similarity 0, got %v", sim)
+	}
+	if sim := cosineSimilarity(nil, []float32{1}); sim != 0 {
+		t.Fatalf("expected empty vector to yield similarity 0, got %v", sim)
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"hash/fnv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// SearchVariant is a named, independently reachable search index competing
+// for a share of live traffic, for A/B testing a re-tuned index without a
+// hard cutover. Weight is a percentage (0-100) of queries also sent to it
+// as a shadow; the router's primary index still serves every one of those
+// responses unless the variant is requested by name.
+type SearchVariant struct {
+	Name   string
+	Weight float64
+	Index  searchindex.Index
+}
+
+var (
+	shadowComparisonsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mycelian_search_shadow_comparisons_total",
+		Help: "Total number of shadow-index comparisons run against the primary search index.",
+	})
+	shadowTop1AgreementTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mycelian_search_shadow_top1_agreement_total",
+		Help: "Number of shadow comparisons where the shadow's top-1 result matched the primary's.",
+	})
+	shadowOverlap = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mycelian_search_shadow_overlap_ratio",
+		Help:    "Jaccard overlap between the primary and shadow result-ID sets for a shadowed query.",
+		Buckets: prometheus.LinearBuckets(0, 0.1, 11),
+	})
+)
+
+// SearchRouter holds a primary SearchIndex plus zero or more named variants,
+// each with a traffic weight. Variant selection is deterministic by
+// hash(memoryID+query), so the same query always lands on the same variant
+// for a given router configuration — useful for reproducing a shadow
+// disagreement. Shadow results never affect what is returned to the caller
+// unless the caller explicitly asks for a variant by name; otherwise they
+// are only compared against the primary's results and logged as metrics.
+type SearchRouter struct {
+	primary  searchindex.Index
+	variants []SearchVariant
+}
+
+// NewSearchRouter constructs a SearchRouter. variants with a Weight <= 0 are
+// ignored; the sum of the remaining weights is clamped to 100.
+func NewSearchRouter(primary searchindex.Index, variants []SearchVariant) *SearchRouter {
+	kept := make([]SearchVariant, 0, len(variants))
+	total := 0.0
+	for _, v := range variants {
+		if v.Weight <= 0 || v.Index == nil {
+			continue
+		}
+		if total+v.Weight > 100 {
+			v.Weight = 100 - total
+		}
+		if v.Weight <= 0 {
+			continue
+		}
+		total += v.Weight
+		kept = append(kept, v)
+	}
+	return &SearchRouter{primary: primary, variants: kept}
+}
+
+// Primary returns the router's primary index.
+func (r *SearchRouter) Primary() searchindex.Index {
+	return r.primary
+}
+
+// Resolve returns the index that should serve the response. If variantName
+// names a configured variant, that variant's index is used explicitly;
+// otherwise the primary index is always used to serve the response (shadow
+// variants only ever observe traffic, they never serve it implicitly).
+func (r *SearchRouter) Resolve(variantName string) searchindex.Index {
+	if variantName == "" {
+		return r.primary
+	}
+	for _, v := range r.variants {
+		if v.Name == variantName {
+			return v.Index
+		}
+	}
+	return r.primary
+}
+
+// shadowVariant deterministically picks the variant (if any) that should
+// shadow-receive this query, based on hash(memoryID+query) mod 100 landing
+// within that variant's weight bucket.
+func (r *SearchRouter) shadowVariant(memoryID, query string) *SearchVariant {
+	if len(r.variants) == 0 {
+		return nil
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(memoryID + ":" + query))
+	bucket := float64(h.Sum32() % 100)
+
+	cumulative := 0.0
+	for i := range r.variants {
+		cumulative += r.variants[i].Weight
+		if bucket < cumulative {
+			return &r.variants[i]
+		}
+	}
+	return nil
+}
+
+// ShadowCompare runs query against the deterministically-selected shadow
+// variant (if one is configured for memoryID+query) and compares its
+// entries against the primary's already-computed hits, recording the
+// Jaccard overlap of their entry-ID sets (order is not considered) and
+// top-1 agreement metrics. It never returns an error or
+// blocks the caller's response; call it in its own goroutine. primaryHits
+// is used read-only for comparison, not mutated.
+func (r *SearchRouter) ShadowCompare(ctx context.Context, actorID, memoryID, query string, vec []float32, topKE int, alpha float32, filter *model.Filter, primaryHits []model.SearchHit) {
+	variant := r.shadowVariant(memoryID, query)
+	if variant == nil {
+		return
+	}
+
+	shadowHits, err := variant.Index.Search(ctx, actorID, memoryID, query, vec, searchindex.SearchOptions{
+		TopK:        topKE,
+		Alpha:       alpha,
+		Filter:      filter,
+		BM25Boost:   1.0,
+		VectorBoost: 1.0,
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("variant", variant.Name).Str("memoryId", memoryID).Msg("shadow search failed")
+		return
+	}
+
+	shadowComparisonsTotal.Inc()
+	shadowOverlap.Observe(entryIDOverlap(primaryHits, shadowHits))
+	if top1Agrees(primaryHits, shadowHits) {
+		shadowTop1AgreementTotal.Inc()
+	}
+	log.Debug().Str("variant", variant.Name).Str("memoryId", memoryID).
+		Int("primaryCount", len(primaryHits)).Int("shadowCount", len(shadowHits)).
+		Msg("shadow search comparison recorded")
+}
+
+// entryIDOverlap returns the Jaccard similarity of a's and b's EntryID sets.
+func entryIDOverlap(a, b []model.SearchHit) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1.0
+	}
+	set := make(map[string]struct{}, len(a))
+	for _, h := range a {
+		set[h.EntryID] = struct{}{}
+	}
+	intersection := 0
+	union := len(set)
+	for _, h := range b {
+		if _, ok := set[h.EntryID]; ok {
+			intersection++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 1.0
+	}
+	return float64(intersection) / float64(union)
+}
+
+// top1Agrees reports whether a and b agree on their highest-ranked EntryID.
+func top1Agrees(a, b []model.SearchHit) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	return a[0].EntryID == b[0].EntryID
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// StreamingIndex is an optional capability a searchindex.Index backend may
+// implement to stream hits as they're produced instead of buffering the
+// full slice before returning. HandleSearchStream uses it when the
+// resolved index implements it; otherwise it falls back to
+// streamSearchShim/streamSearchContextsShim, which wrap the ordinary
+// Search/SearchContexts calls in a goroutine so every backend can be
+// treated identically by the streaming handler.
+type StreamingIndex interface {
+	SearchStream(ctx context.Context, actorID, memoryID, query string, vec []float32, opts searchindex.SearchOptions) (<-chan model.SearchHit, <-chan error)
+	SearchContextsStream(ctx context.Context, actorID, memoryID, query string, vec []float32, opts searchindex.SearchContextsOptions) (<-chan model.ContextHit, <-chan error)
+}
+
+// entryStream returns a channel of entry hits for idx, using idx's native
+// SearchStream if it implements StreamingIndex, or streamSearchShim
+// otherwise.
+func entryStream(ctx context.Context, idx searchindex.Index, actorID, memoryID, query string, vec []float32, opts searchindex.SearchOptions) (<-chan model.SearchHit, <-chan error) {
+	if streaming, ok := idx.(StreamingIndex); ok {
+		return streaming.SearchStream(ctx, actorID, memoryID, query, vec, opts)
+	}
+	return streamSearchShim(ctx, idx, actorID, memoryID, query, vec, opts)
+}
+
+// contextStream returns a channel of context hits for idx, using idx's
+// native SearchContextsStream if it implements StreamingIndex, or
+// streamSearchContextsShim otherwise.
+func contextStream(ctx context.Context, idx searchindex.Index, actorID, memoryID, query string, vec []float32, opts searchindex.SearchContextsOptions) (<-chan model.ContextHit, <-chan error) {
+	if streaming, ok := idx.(StreamingIndex); ok {
+		return streaming.SearchContextsStream(ctx, actorID, memoryID, query, vec, opts)
+	}
+	return streamSearchContextsShim(ctx, idx, actorID, memoryID, query, vec, opts)
+}
+
+// streamSearchShim adapts idx.Search's slice return into the channel shape
+// StreamingIndex callers expect: it runs Search once up front, then
+// replays each hit onto hitCh, honoring ctx cancellation while doing so.
+func streamSearchShim(ctx context.Context, idx searchindex.Index, actorID, memoryID, query string, vec []float32, opts searchindex.SearchOptions) (<-chan model.SearchHit, <-chan error) {
+	hitCh := make(chan model.SearchHit)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(hitCh)
+		defer close(errCh)
+		hits, err := idx.Search(ctx, actorID, memoryID, query, vec, opts)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		for _, hit := range hits {
+			select {
+			case hitCh <- hit:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return hitCh, errCh
+}
+
+// streamSearchContextsShim is streamSearchShim's counterpart for
+// idx.SearchContexts.
+func streamSearchContextsShim(ctx context.Context, idx searchindex.Index, actorID, memoryID, query string, vec []float32, opts searchindex.SearchContextsOptions) (<-chan model.ContextHit, <-chan error) {
+	ctxCh := make(chan model.ContextHit)
+	errCh := make(chan error, 1)
+	go func() {
+		defer close(ctxCh)
+		defer close(errCh)
+		hits, err := idx.SearchContexts(ctx, actorID, memoryID, query, vec, opts)
+		if err != nil {
+			errCh <- err
+			return
+		}
+		for _, hit := range hits {
+			select {
+			case ctxCh <- hit:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ctxCh, errCh
+}
@@ -0,0 +1,224 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	respond "github.com/mycelian/mycelian-memory/server/internal/api/respond"
+	"github.com/mycelian/mycelian-memory/server/internal/auth"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// sseHeartbeatInterval is how often HandleSearchStream writes a comment
+// frame while waiting on a slow stage, so intermediary proxies don't time
+// out an idle connection.
+const sseHeartbeatInterval = 15 * time.Second
+
+// HandleSearchStream handles POST /api/search/stream: it runs the same
+// pipeline as HandleSearch but emits each stage as a Server-Sent Events
+// frame as soon as it completes, so clients can render top hits while
+// slower stages are still running. It does not support federated
+// (multi-memory/vault) search or result diversification, since both
+// require buffering the full candidate set before anything can be
+// emitted, defeating the point of streaming. QueryHook.AfterSearch, result
+// fusion, and SearchRouter shadow comparison are not applied to streamed
+// hits.
+func (h *SearchHandler) HandleSearchStream(w http.ResponseWriter, r *http.Request) {
+	apiKey, err := auth.ExtractAPIKey(r)
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	actorInfo, err := h.authorizer.Authorize(r.Context(), apiKey, "memory.search", "default")
+	if err != nil {
+		respond.WriteError(w, http.StatusUnauthorized, "Unauthorized: "+err.Error())
+		return
+	}
+
+	req, err := decodeSearchRequest(w, r)
+	if err != nil {
+		respond.WriteBadRequest(w, err.Error())
+		return
+	}
+	if h.emb == nil || h.idx == nil {
+		respond.WriteError(w, http.StatusServiceUnavailable, "search not configured")
+		return
+	}
+	if req.VaultID != "" || len(req.MemoryIDs) > 0 {
+		respond.WriteBadRequest(w, "streaming search does not support federated (vaultId or multi-memory) requests")
+		return
+	}
+	if req.Diversify {
+		respond.WriteBadRequest(w, "streaming search does not support diversify")
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		respond.WriteError(w, http.StatusInternalServerError, "streaming not supported")
+		return
+	}
+
+	if h.hooks != nil {
+		req, err = h.hooks.BeforeEmbed(r.Context(), req)
+		if err != nil {
+			log.Error().Err(err).Msg("query hook failed")
+			respond.WriteError(w, http.StatusInternalServerError, "query hook failed")
+			return
+		}
+	}
+
+	memoryIDs, err := h.resolveMemoryIDs(r.Context(), actorInfo.ActorID, req)
+	if err != nil || len(memoryIDs) != 1 {
+		log.Error().Err(err).Str("vaultId", req.VaultID).Msg("resolving memory IDs failed")
+		respond.WriteError(w, http.StatusInternalServerError, "resolving memories unavailable")
+		return
+	}
+	memoryID := memoryIDs[0]
+
+	ctx := r.Context()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	vec, err := h.emb.Embed(ctx, req.EmbedText())
+	if err != nil {
+		log.Error().Err(err).Str("query", req.Query).Msg("embedding failed")
+		writeSSEEvent(w, "error", map[string]string{"message": "embedding service unavailable"})
+		flusher.Flush()
+		return
+	}
+	writeSSEEvent(w, "embedding", map[string]int{"dimensions": len(vec)})
+	flusher.Flush()
+
+	alpha := h.alpha
+	if req.alphaProvided {
+		alpha = float32(*req.Alpha)
+	}
+	bm25Boost := float32(*req.BM25Boost)
+	vectorBoost := float32(*req.VectorBoost)
+
+	idx := h.idx
+	if h.router != nil {
+		idx = h.router.Resolve(req.Variant)
+	}
+
+	var entryCount int
+	if *req.TopKE > 0 {
+		hitCh, errCh := entryStream(ctx, idx, actorInfo.ActorID, memoryID, req.Query, vec, searchindex.SearchOptions{
+			TopK:              *req.TopKE,
+			Alpha:             alpha,
+			IncludeRawEntries: req.IncludeRawEntries,
+			Filter:            req.Filter,
+			Explain:           req.Explain,
+			BM25Boost:         bm25Boost,
+			VectorBoost:       vectorBoost,
+		})
+		for hitCh != nil || errCh != nil {
+			select {
+			case hit, ok := <-hitCh:
+				if !ok {
+					hitCh = nil
+					continue
+				}
+				writeSSEEvent(w, "entry", hit)
+				flusher.Flush()
+				entryCount++
+			case err, ok := <-errCh:
+				if !ok {
+					errCh = nil
+					continue
+				}
+				if err != nil {
+					log.Error().Err(err).Str("memoryId", memoryID).Msg("streaming search failed")
+					writeSSEEvent(w, "error", map[string]string{"message": "search service unavailable"})
+					flusher.Flush()
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				flusher.Flush()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	latestCtx, latestTs, err := idx.LatestContext(ctx, actorInfo.ActorID, memoryID)
+	if err != nil {
+		log.Error().Err(err).Str("memoryId", memoryID).Msg("latest context fetch failed")
+		writeSSEEvent(w, "error", map[string]string{"message": "latest context unavailable"})
+		flusher.Flush()
+		return
+	}
+	writeSSEEvent(w, "latest_context", map[string]string{
+		"context":   latestCtx,
+		"timestamp": latestTs.Format(time.RFC3339),
+	})
+	flusher.Flush()
+
+	var contextCount int
+	ctxCh, cerrCh := contextStream(ctx, idx, actorInfo.ActorID, memoryID, req.Query, vec, searchindex.SearchContextsOptions{
+		TopK:        *req.TopKC,
+		Alpha:       alpha,
+		Filter:      req.Filter,
+		Explain:     req.Explain,
+		BM25Boost:   bm25Boost,
+		VectorBoost: vectorBoost,
+	})
+	for ctxCh != nil || cerrCh != nil {
+		select {
+		case hit, ok := <-ctxCh:
+			if !ok {
+				ctxCh = nil
+				continue
+			}
+			writeSSEEvent(w, "context", map[string]any{
+				"context":   hit.Context,
+				"timestamp": hit.Timestamp.Format(time.RFC3339),
+				"score":     hit.Score,
+			})
+			flusher.Flush()
+			contextCount++
+		case err, ok := <-cerrCh:
+			if !ok {
+				cerrCh = nil
+				continue
+			}
+			if err != nil {
+				log.Error().Err(err).Str("memoryId", memoryID).Msg("streaming context search failed")
+				writeSSEEvent(w, "error", map[string]string{"message": "context search unavailable"})
+				flusher.Flush()
+				return
+			}
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case <-ctx.Done():
+			return
+		}
+	}
+
+	writeSSEEvent(w, "done", map[string]int{"entryCount": entryCount, "contextCount": contextCount})
+	flusher.Flush()
+}
+
+// writeSSEEvent writes a single Server-Sent Events frame with the given
+// event name and a JSON-encoded payload.
+func writeSSEEvent(w http.ResponseWriter, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		data = []byte(`{}`)
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
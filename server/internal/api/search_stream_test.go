@@ -0,0 +1,97 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// readSSEEvents splits a Server-Sent Events body into event-name/data pairs,
+// in the order they were written.
+func readSSEEvents(t *testing.T, body string) []string {
+	t.Helper()
+	var events []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if name, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	return events
+}
+
+func TestHandleSearchStream_EmitsStagesInOrderWithDone(t *testing.T) {
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":1,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/api/search/stream", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearchStream(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("expected text/event-stream content type, got %q", ct)
+	}
+
+	events := readSSEEvents(t, w.Body.String())
+	want := []string{"embedding", "entry", "latest_context", "context", "done"}
+	if len(events) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, events)
+	}
+	for i, name := range want {
+		if events[i] != name {
+			t.Fatalf("expected event %d to be %q, got %q (all: %v)", i, name, events[i], events)
+		}
+	}
+}
+
+func TestHandleSearchStream_TopKEZeroSkipsEntryEvents(t *testing.T) {
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":0,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/api/search/stream", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearchStream(w, req)
+
+	events := readSSEEvents(t, w.Body.String())
+	for _, name := range events {
+		if name == "entry" {
+			t.Fatalf("expected no entry events when top_ke=0, got %v", events)
+		}
+	}
+}
+
+func TestHandleSearchStream_RejectsFederatedRequest(t *testing.T) {
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":["m1","m2"],"query":"hello"}`)
+	req := httptest.NewRequest("POST", "/api/search/stream", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearchStream(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for federated request, got %d", w.Code)
+	}
+}
+
+func TestHandleSearchStream_RejectsDiversify(t *testing.T) {
+	h, _ := NewSearchHandler(&mockEmbedder{}, &mockSearch{}, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","diversify":true}`)
+	req := httptest.NewRequest("POST", "/api/search/stream", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearchStream(w, req)
+
+	if w.Code != 400 {
+		t.Fatalf("expected 400 for diversify request, got %d", w.Code)
+	}
+}
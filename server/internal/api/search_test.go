@@ -2,8 +2,12 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
 )
 
 func TestSearchRequestValidateDefaults(t *testing.T) {
@@ -35,6 +39,158 @@ func TestDecodeSearchRequest(t *testing.T) {
 	}
 }
 
+func TestSearchRequestValidateFusionDefaults(t *testing.T) {
+	req := SearchRequest{MemoryID: "m1", Query: "test"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Fusion != FusionNone || req.K == nil || *req.K != 60 || req.Alpha == nil || *req.Alpha != 0.5 {
+		t.Fatalf("fusion defaults not applied correctly: fusion=%v, k=%v, alpha=%v", req.Fusion, req.K, req.Alpha)
+	}
+}
+
+func TestSearchRequestValidateFusionErrors(t *testing.T) {
+	badFusion := SearchRequest{MemoryID: "m1", Query: "test", Fusion: "bogus"}
+	if err := badFusion.Validate(); err == nil {
+		t.Fatalf("expected error for unknown fusion mode")
+	}
+
+	zeroK := 0
+	badK := SearchRequest{MemoryID: "m1", Query: "test", K: &zeroK}
+	if err := badK.Validate(); err == nil {
+		t.Fatalf("expected error for non-positive k")
+	}
+
+	outOfRangeAlpha := 1.5
+	badAlpha := SearchRequest{MemoryID: "m1", Query: "test", Alpha: &outOfRangeAlpha}
+	if err := badAlpha.Validate(); err == nil {
+		t.Fatalf("expected error for out-of-range alpha")
+	}
+}
+
+func TestSearchRequestValidateFilter(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	t.Run("valid combined filter", func(t *testing.T) {
+		req := SearchRequest{MemoryID: "m1", Query: "test", Filter: &model.Filter{
+			TimeRange: &model.TimeRange{From: earlier, To: now},
+			Kinds:     []string{"summary"},
+			Tags:      &model.TagFilter{AllOf: []string{"urgent"}},
+			Metadata:  map[string]model.MetadataPredicate{"priority": {Gte: 5}},
+		}}
+		if err := req.Validate(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("invalid time range rejected", func(t *testing.T) {
+		req := SearchRequest{MemoryID: "m1", Query: "test", Filter: &model.Filter{
+			TimeRange: &model.TimeRange{From: now, To: earlier},
+		}}
+		if err := req.Validate(); err == nil {
+			t.Fatalf("expected error for invalid time range")
+		}
+	})
+
+	t.Run("invalid metadata operator rejected", func(t *testing.T) {
+		req := SearchRequest{MemoryID: "m1", Query: "test", Filter: &model.Filter{
+			Metadata: map[string]model.MetadataPredicate{"priority": {}},
+		}}
+		if err := req.Validate(); err == nil {
+			t.Fatalf("expected error for metadata predicate with no operator")
+		}
+	})
+}
+
+func TestSearchRequestValidateDiversifyDefaults(t *testing.T) {
+	req := SearchRequest{MemoryID: "m1", Query: "test"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Lambda == nil || *req.Lambda != 0.5 {
+		t.Fatalf("expected default lambda 0.5, got %v", req.Lambda)
+	}
+	if req.PoolSize == nil || *req.PoolSize != 20 {
+		t.Fatalf("expected default pool_size 20 (3*top_kc floored at 20), got %v", req.PoolSize)
+	}
+}
+
+func TestSearchRequestValidateDiversifyErrors(t *testing.T) {
+	badLambda := 1.5
+	req := SearchRequest{MemoryID: "m1", Query: "test", Lambda: &badLambda}
+	if err := req.Validate(); err == nil {
+		t.Fatalf("expected error for out-of-range lambda")
+	}
+
+	smallPool := 1
+	topKC := 5
+	req2 := SearchRequest{MemoryID: "m1", Query: "test", TopKC: &topKC, PoolSize: &smallPool}
+	if err := req2.Validate(); err == nil {
+		t.Fatalf("expected error for pool_size smaller than top_kc")
+	}
+}
+
+func TestSearchRequestUnmarshal_SingleMemoryID(t *testing.T) {
+	var req SearchRequest
+	if err := json.Unmarshal([]byte(`{"memoryId":"m1","query":"q"}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if req.MemoryID != "m1" || len(req.MemoryIDs) != 0 {
+		t.Fatalf("expected single memoryId m1, got MemoryID=%q MemoryIDs=%v", req.MemoryID, req.MemoryIDs)
+	}
+}
+
+func TestSearchRequestUnmarshal_MemoryIDArray(t *testing.T) {
+	var req SearchRequest
+	if err := json.Unmarshal([]byte(`{"memoryId":["m1","m2"],"query":"q"}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(req.MemoryIDs) != 2 || req.MemoryIDs[0] != "m1" || req.MemoryIDs[1] != "m2" {
+		t.Fatalf("expected memoryIds [m1,m2], got %v", req.MemoryIDs)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestSearchRequestUnmarshal_VaultIDOnly(t *testing.T) {
+	var req SearchRequest
+	if err := json.Unmarshal([]byte(`{"vaultId":"v1","query":"q"}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+}
+
+func TestSearchRequestValidate_RequiresMemoryOrVault(t *testing.T) {
+	req := SearchRequest{Query: "q"}
+	if err := req.Validate(); err == nil {
+		t.Fatalf("expected error when neither memoryId nor vaultId is set")
+	}
+}
+
+func TestSearchRequestValidate_ExplainDefaultsFalse(t *testing.T) {
+	req := SearchRequest{MemoryID: "m1", Query: "test"}
+	if err := req.Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Explain {
+		t.Fatalf("expected explain to default to false")
+	}
+}
+
+func TestSearchRequestUnmarshal_Explain(t *testing.T) {
+	var req SearchRequest
+	if err := json.Unmarshal([]byte(`{"memoryId":"m1","query":"q","explain":true}`), &req); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if !req.Explain {
+		t.Fatalf("expected explain true")
+	}
+}
+
 func TestSearchRequestValidateRanges(t *testing.T) {
 	tests := []struct {
 		name    string
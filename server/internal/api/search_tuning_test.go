@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mycelian/mycelian-memory/server/internal/model"
+)
+
+func TestHandleSearch_DefaultBoostsArePassedThrough(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":2,"top_kc":1}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if srch.lastBM25Boost != 1.0 || srch.lastVectorBoost != 1.0 {
+		t.Fatalf("expected default boosts of 1.0, got bm25=%v vector=%v", srch.lastBM25Boost, srch.lastVectorBoost)
+	}
+	if srch.lastAlpha != 0.6 {
+		t.Fatalf("expected handler's configured alpha 0.6 when alpha is unset, got %v", srch.lastAlpha)
+	}
+}
+
+func TestHandleSearch_ExplicitAlphaAndBoostsOverrideHandlerDefault(t *testing.T) {
+	emb := &mockEmbedder{}
+	srch := &mockSearch{}
+	auth := &mockAuthorizer{}
+	h, _ := NewSearchHandler(emb, srch, 0.6, auth)
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":2,"top_kc":1,"alpha":0.9,"bm25_boost":2.0,"vector_boost":0.5}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if srch.lastAlpha != 0.9 {
+		t.Fatalf("expected alpha override 0.9, got %v", srch.lastAlpha)
+	}
+	if srch.lastBM25Boost != 2.0 || srch.lastVectorBoost != 0.5 {
+		t.Fatalf("expected boost overrides bm25=2.0 vector=0.5, got bm25=%v vector=%v", srch.lastBM25Boost, srch.lastVectorBoost)
+	}
+}
+
+func TestSearchRequestValidate_RejectsNonPositiveBoosts(t *testing.T) {
+	bad := 0.0
+	req := &SearchRequest{MemoryID: "m1", Query: "q", BM25Boost: &bad}
+	if err := req.Validate(); err == nil {
+		t.Fatalf("expected error for bm25_boost <= 0")
+	}
+}
+
+func TestSearchRouter_ResolveExplicitVariant(t *testing.T) {
+	primary := &mockSearch{}
+	shadow := &mockSearch{}
+	router := NewSearchRouter(primary, []SearchVariant{{Name: "candidate", Weight: 50, Index: shadow}})
+
+	if router.Resolve("candidate") != shadow {
+		t.Fatalf("expected Resolve(\"candidate\") to return the named variant's index")
+	}
+	if router.Resolve("") != primary {
+		t.Fatalf("expected Resolve(\"\") to return the primary index")
+	}
+	if router.Resolve("unknown") != primary {
+		t.Fatalf("expected Resolve of an unknown variant to fall back to primary")
+	}
+}
+
+func TestSearchRouter_ShadowVariant_DeterministicForSameInput(t *testing.T) {
+	router := NewSearchRouter(&mockSearch{}, []SearchVariant{{Name: "v1", Weight: 100, Index: &mockSearch{}}})
+
+	first := router.shadowVariant("mem-1", "hello world")
+	second := router.shadowVariant("mem-1", "hello world")
+	if first == nil || second == nil || first.Name != second.Name {
+		t.Fatalf("expected deterministic shadow variant selection for identical input")
+	}
+}
+
+func TestSearchRouter_ShadowVariant_NoVariantsReturnsNil(t *testing.T) {
+	router := NewSearchRouter(&mockSearch{}, nil)
+	if v := router.shadowVariant("mem-1", "q"); v != nil {
+		t.Fatalf("expected no shadow variant when none are configured, got %+v", v)
+	}
+}
+
+func TestEntryIDOverlap_IdenticalSetsIsOne(t *testing.T) {
+	a := []model.SearchHit{{EntryID: "e1"}, {EntryID: "e2"}}
+	b := []model.SearchHit{{EntryID: "e2"}, {EntryID: "e1"}}
+	if got := entryIDOverlap(a, b); got != 1.0 {
+		t.Fatalf("expected overlap 1.0 for identical sets, got %v", got)
+	}
+}
+
+func TestEntryIDOverlap_DisjointSetsIsZero(t *testing.T) {
+	a := []model.SearchHit{{EntryID: "e1"}}
+	b := []model.SearchHit{{EntryID: "e2"}}
+	if got := entryIDOverlap(a, b); got != 0.0 {
+		t.Fatalf("expected overlap 0.0 for disjoint sets, got %v", got)
+	}
+}
+
+func TestTop1Agrees_SameTopEntryIsTrue(t *testing.T) {
+	a := []model.SearchHit{{EntryID: "e1"}}
+	b := []model.SearchHit{{EntryID: "e1"}, {EntryID: "e2"}}
+	if !top1Agrees(a, b) {
+		t.Fatalf("expected top1Agrees to be true when both rank e1 first")
+	}
+}
+
+func TestTop1Agrees_DifferentTopEntryIsFalse(t *testing.T) {
+	a := []model.SearchHit{{EntryID: "e1"}}
+	b := []model.SearchHit{{EntryID: "e2"}}
+	if top1Agrees(a, b) {
+		t.Fatalf("expected top1Agrees to be false when top entries differ")
+	}
+}
+
+func TestHandleSearch_DiversifyRequestsVectorsAndReranksEntries(t *testing.T) {
+	srch := &mockSearch{hits: []model.SearchHit{
+		{EntryID: "d0", Score: 0.95, Vector: []float32{1, 0, 0}},
+		{EntryID: "d1", Score: 0.94, Vector: []float32{0.99, 0.01, 0}},
+		{EntryID: "d2", Score: 0.80, Vector: []float32{0, 1, 0}},
+	}}
+	h, _ := NewSearchHandler(&mockEmbedder{}, srch, 0.6, &mockAuthorizer{})
+
+	body := bytes.NewBufferString(`{"memoryId":"m1","query":"hello","top_ke":2,"top_kc":1,"diversify":true,"lambda":0.5}`)
+	req := httptest.NewRequest("POST", "/v0/search", body)
+	req.Header.Set("Authorization", "Bearer test-api-key")
+	w := httptest.NewRecorder()
+	h.HandleSearch(w, req)
+
+	if w.Code != 200 {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if !srch.lastIncludeVectors {
+		t.Fatalf("expected Search to be called with includeVectors=true when diversify is requested")
+	}
+	var resp struct {
+		Entries []model.SearchHit `json:"entries"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(resp.Entries) != 2 || resp.Entries[0].EntryID != "d0" || resp.Entries[1].EntryID != "d2" {
+		t.Fatalf("expected diversified entries [d0, d2], got %+v", resp.Entries)
+	}
+}
+
+func TestSearchRouter_ShadowCompare_RunsAgainstShadowIndex(t *testing.T) {
+	shadow := &mockSearch{}
+	router := NewSearchRouter(&mockSearch{}, []SearchVariant{{Name: "v1", Weight: 100, Index: shadow}})
+
+	primaryHits := []model.SearchHit{{EntryID: "e1"}}
+	router.ShadowCompare(context.Background(), "actor", "mem-1", "query", []float32{1, 2}, 5, 0.6, nil, primaryHits)
+
+	if shadow.calls != 1 {
+		t.Fatalf("expected shadow index to be queried once, got %d calls", shadow.calls)
+	}
+}
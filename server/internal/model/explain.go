@@ -0,0 +1,22 @@
+package model
+
+// TermContribution describes how much a single matched query term
+// contributed to a result's lexical score.
+type TermContribution struct {
+	Term         string  `json:"term"`
+	IDF          float64 `json:"idf"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Explanation is a score breakdown attached to a search result when the
+// request sets SearchRequest.Explain. It is omitted entirely otherwise so
+// the default response shape and token footprint are unaffected.
+type Explanation struct {
+	SemanticScore float64            `json:"semanticScore"`
+	LexicalScore  float64            `json:"lexicalScore"`
+	FusionWeight  float64            `json:"fusionWeight"`
+	MatchedTerms  []TermContribution `json:"matchedTerms,omitempty"`
+	// TopLexicalField is the entry field ("summary" or "rawEntry") that
+	// produced the strongest lexical match. Empty for context shards.
+	TopLexicalField string `json:"topLexicalField,omitempty"`
+}
@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeRange restricts results to Entry.CreationTime / context timestamps
+// falling within [From, To]. Either bound may be zero to leave it open.
+type TimeRange struct {
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+}
+
+// TagFilter matches entry tags using set semantics. An entry must satisfy
+// every non-empty clause to pass.
+type TagFilter struct {
+	AllOf  []string `json:"all_of,omitempty"`
+	AnyOf  []string `json:"any_of,omitempty"`
+	NoneOf []string `json:"none_of,omitempty"`
+}
+
+// MetadataPredicate is a single equality/in/gte/lte test against an entry
+// metadata key. Exactly one of Eq, In, Gte, Lte should be set.
+type MetadataPredicate struct {
+	Eq  any   `json:"eq,omitempty"`
+	In  []any `json:"in,omitempty"`
+	Gte any   `json:"gte,omitempty"`
+	Lte any   `json:"lte,omitempty"`
+}
+
+// Filter is the structured filter DSL accepted by SearchRequest and pushed
+// down into the search backend. Predicates are evaluated by the backend
+// rather than applied client-side, so count, top_ke, and top_kc remain
+// correct against the filtered set.
+type Filter struct {
+	TimeRange *TimeRange                   `json:"time_range,omitempty"`
+	Kinds     []string                     `json:"kinds,omitempty"`
+	Tags      *TagFilter                   `json:"tags,omitempty"`
+	Metadata  map[string]MetadataPredicate `json:"metadata,omitempty"`
+}
+
+// Validate checks the filter for unknown operators and invalid ranges.
+func (f *Filter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.TimeRange != nil {
+		tr := f.TimeRange
+		if !tr.From.IsZero() && !tr.To.IsZero() && tr.From.After(tr.To) {
+			return fmt.Errorf("time_range.from must not be after time_range.to")
+		}
+	}
+	for key, pred := range f.Metadata {
+		set := 0
+		if pred.Eq != nil {
+			set++
+		}
+		if pred.In != nil {
+			set++
+		}
+		if pred.Gte != nil {
+			set++
+		}
+		if pred.Lte != nil {
+			set++
+		}
+		if set == 0 {
+			return fmt.Errorf("metadata predicate for %q must set eq, in, gte, or lte", key)
+		}
+		if set > 1 {
+			return fmt.Errorf("metadata predicate for %q must set exactly one operator", key)
+		}
+	}
+	return nil
+}
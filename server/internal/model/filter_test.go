@@ -0,0 +1,53 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterValidate_Nil(t *testing.T) {
+	var f *Filter
+	if err := f.Validate(); err != nil {
+		t.Fatalf("expected nil filter to validate, got %v", err)
+	}
+}
+
+func TestFilterValidate_TimeRange(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	valid := &Filter{TimeRange: &TimeRange{From: earlier, To: now}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("expected valid time range, got %v", err)
+	}
+
+	invalid := &Filter{TimeRange: &TimeRange{From: now, To: earlier}}
+	if err := invalid.Validate(); err == nil {
+		t.Fatalf("expected error for from after to")
+	}
+}
+
+func TestFilterValidate_Metadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		pred    MetadataPredicate
+		wantErr bool
+	}{
+		{"eq only", MetadataPredicate{Eq: "x"}, false},
+		{"in only", MetadataPredicate{In: []any{"x", "y"}}, false},
+		{"gte only", MetadataPredicate{Gte: 5}, false},
+		{"lte only", MetadataPredicate{Lte: 5}, false},
+		{"none set", MetadataPredicate{}, true},
+		{"two operators", MetadataPredicate{Eq: "x", Gte: 5}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &Filter{Metadata: map[string]MetadataPredicate{"k": tt.pred}}
+			err := f.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// JobSummary is the admin-facing view of a pending outbox job.
+type JobSummary struct {
+	ID             int64     `json:"id"`
+	Op             string    `json:"op"`
+	AggregateID    string    `json:"aggregateId"`
+	Attempts       int       `json:"attempts"`
+	FirstAttemptAt time.Time `json:"firstAttemptAt,omitempty"`
+	NextVisibleAt  time.Time `json:"nextVisibleAt"`
+}
+
+// JobDetail adds the payload and last error to JobSummary.
+type JobDetail struct {
+	JobSummary
+	Payload   map[string]interface{} `json:"payload"`
+	LastError string                 `json:"lastError,omitempty"`
+}
+
+// Stats summarizes outbox queue depth and job age, for operator dashboards.
+type Stats struct {
+	PendingCount    int     `json:"pendingCount"`
+	DeadLetterCount int     `json:"deadLetterCount"`
+	OldestAgeSecs   float64 `json:"oldestAgeSeconds"`
+	P50AgeSecs      float64 `json:"p50AgeSeconds"`
+	P99AgeSecs      float64 `json:"p99AgeSeconds"`
+}
+
+// ErrJobNotFound is returned by the admin accessors when the requested job
+// id does not exist in the table being queried.
+var ErrJobNotFound = fmt.Errorf("outbox: job not found")
+
+// ErrNotRescheduleEligible is returned by RetryDeadLetterJob when the job
+// was dead-lettered for a terminal reason (e.g. already-exists, unknown op)
+// rather than a transient transport error, so replaying it would just
+// reproduce the same failure.
+var ErrNotRescheduleEligible = fmt.Errorf("outbox: job was dead-lettered for a non-retryable reason")
+
+// ListJobs returns up to limit pending jobs ordered oldest-first, optionally
+// filtered by op. A limit <= 0 defaults to 100.
+func (w *Worker) ListJobs(ctx context.Context, opFilter string, limit int) ([]JobSummary, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := `
+		SELECT id, op, aggregate_id, attempts, first_attempt_at, next_visible_at
+		FROM outbox`
+	args := []interface{}{}
+	if opFilter != "" {
+		query += ` WHERE op = $1`
+		args = append(args, opFilter)
+	}
+	query += fmt.Sprintf(` ORDER BY id LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := w.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []JobSummary
+	for rows.Next() {
+		var s JobSummary
+		var firstAttemptAt sql.NullTime
+		if err := rows.Scan(&s.ID, &s.Op, &s.AggregateID, &s.Attempts, &firstAttemptAt, &s.NextVisibleAt); err != nil {
+			return nil, err
+		}
+		if firstAttemptAt.Valid {
+			s.FirstAttemptAt = firstAttemptAt.Time
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+// GetJob fetches a single pending job with its payload and last error. It
+// only looks at the outbox table; a job that has already been dead-lettered
+// is reached through the dead-letter endpoints instead.
+func (w *Worker) GetJob(ctx context.Context, id int64) (*JobDetail, error) {
+	row := w.db.QueryRowContext(ctx, `
+		SELECT id, op, aggregate_id, payload, attempts, first_attempt_at, next_visible_at, last_error
+		FROM outbox WHERE id = $1`, id)
+
+	var d JobDetail
+	var payload []byte
+	var firstAttemptAt sql.NullTime
+	var lastError sql.NullString
+	if err := row.Scan(&d.ID, &d.Op, &d.AggregateID, &payload, &d.Attempts, &firstAttemptAt, &d.NextVisibleAt, &lastError); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrJobNotFound
+		}
+		return nil, err
+	}
+	if firstAttemptAt.Valid {
+		d.FirstAttemptAt = firstAttemptAt.Time
+	}
+	d.LastError = lastError.String
+	if err := unmarshalPayload(payload, &d.Payload); err != nil {
+		return nil, fmt.Errorf("job %d: decoding payload: %w", id, err)
+	}
+	return &d, nil
+}
+
+// RetryJob forces a pending job to be picked up on the next poll: it sets
+// NextVisibleAt to now. It does not reset Attempts, so the retry still
+// counts toward RetryPolicy.MaxAttempts.
+func (w *Worker) RetryJob(ctx context.Context, id int64) error {
+	res, err := w.db.ExecContext(ctx, `UPDATE outbox SET next_visible_at = $2 WHERE id = $1`, id, time.Now())
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(res)
+}
+
+// RetryDeadLetterJob moves a dead-lettered job back into the outbox table,
+// ready to be picked up immediately, with its attempt count reset. It
+// refuses to replay a job whose last_error was classified as terminal (see
+// rescheduleEligible) — the same operation would just fail the same way
+// again — returning ErrNotRescheduleEligible in that case.
+func (w *Worker) RetryDeadLetterJob(ctx context.Context, id int64) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	row := tx.QueryRowContext(ctx, `
+		SELECT op, aggregate_id, payload, last_error FROM outbox_dead_letter WHERE id = $1`, id)
+	var opVal, aggregateID, lastError string
+	var payload []byte
+	if err := row.Scan(&opVal, &aggregateID, &payload, &lastError); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrJobNotFound
+		}
+		return err
+	}
+	if err := checkRescheduleEligible(lastError); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox (id, op, aggregate_id, payload, attempts, next_visible_at)
+		VALUES ($1, $2, $3, $4, 0, $5)`, id, opVal, aggregateID, payload, time.Now()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM outbox_dead_letter WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteDeadLetterJob permanently discards a dead-lettered job.
+func (w *Worker) DeleteDeadLetterJob(ctx context.Context, id int64) error {
+	res, err := w.db.ExecContext(ctx, `DELETE FROM outbox_dead_letter WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	return requireRowsAffected(res)
+}
+
+// GetStats reports current queue depth and job age percentiles.
+func (w *Worker) GetStats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	if err := w.db.QueryRowContext(ctx, `SELECT count(*) FROM outbox`).Scan(&stats.PendingCount); err != nil {
+		return Stats{}, err
+	}
+	if err := w.db.QueryRowContext(ctx, `SELECT count(*) FROM outbox_dead_letter`).Scan(&stats.DeadLetterCount); err != nil {
+		return Stats{}, err
+	}
+
+	rows, err := w.db.QueryContext(ctx, `SELECT first_attempt_at FROM outbox WHERE first_attempt_at IS NOT NULL`)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var ages []float64
+	for rows.Next() {
+		var t time.Time
+		if err := rows.Scan(&t); err != nil {
+			return Stats{}, err
+		}
+		ages = append(ages, now.Sub(t).Seconds())
+	}
+	if err := rows.Err(); err != nil {
+		return Stats{}, err
+	}
+
+	stats.OldestAgeSecs, stats.P50AgeSecs, stats.P99AgeSecs = ageStats(ages)
+	return stats, nil
+}
+
+// ageStats computes the oldest, p50, and p99 of a set of ages (in seconds).
+func ageStats(ages []float64) (oldest, p50, p99 float64) {
+	if len(ages) == 0 {
+		return 0, 0, 0
+	}
+	sorted := append([]float64(nil), ages...)
+	sort.Float64s(sorted)
+	oldest = sorted[len(sorted)-1]
+	p50 = percentile(sorted, 0.50)
+	p99 = percentile(sorted, 0.99)
+	return oldest, p50, p99
+}
+
+// percentile returns the p-th percentile (0..1) of a pre-sorted slice as the
+// element at index floor(p*(len-1)), without interpolation.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// checkRescheduleEligible applies the same terminal-vs-transient
+// classification the drain loop uses (rescheduleEligible) to a
+// dead-lettered job's recorded last_error, so the admin replay path refuses
+// to re-run a job that is guaranteed to fail the same way again.
+func checkRescheduleEligible(lastError string) error {
+	if lastError == "" {
+		return nil
+	}
+	if !rescheduleEligible(fmt.Errorf("%s", lastError)) {
+		return ErrNotRescheduleEligible
+	}
+	return nil
+}
+
+func requireRowsAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
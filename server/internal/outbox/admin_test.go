@@ -0,0 +1,28 @@
+package outbox
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckRescheduleEligible_TerminalErrorIsRejected demonstrates that a
+// job dead-lettered for a terminal reason (e.g. an already-exists 422) is
+// not eligible for replay: it would just fail identically again.
+func TestCheckRescheduleEligible_TerminalErrorIsRejected(t *testing.T) {
+	err := checkRescheduleEligible("status code: 422 object already exists")
+	assert.Equal(t, ErrNotRescheduleEligible, err)
+}
+
+// TestCheckRescheduleEligible_TransportErrorIsAccepted demonstrates that a
+// job dead-lettered after exhausting retries on a transient transport error
+// is eligible for replay.
+func TestCheckRescheduleEligible_TransportErrorIsAccepted(t *testing.T) {
+	err := checkRescheduleEligible("connection refused")
+	assert.NoError(t, err)
+}
+
+func TestCheckRescheduleEligible_EmptyLastErrorIsAccepted(t *testing.T) {
+	err := checkRescheduleEligible("")
+	assert.NoError(t, err)
+}
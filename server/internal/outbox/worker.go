@@ -0,0 +1,555 @@
+// Package outbox drains the transactional outbox table, embedding and
+// indexing entries/contexts written by the API layer in the same
+// transaction as the primary write.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+
+	emb "github.com/mycelian/mycelian-memory/server/internal/embeddings"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
+)
+
+// op identifies the kind of outbox row being processed.
+type op string
+
+const (
+	OpUpsertEntry   op = "upsert_entry"
+	OpUpsertContext op = "upsert_context"
+)
+
+// job is a single outbox row. FirstAttemptAt, Attempts, NextVisibleAt, and
+// RescheduleEligible track retry state across polling cycles; see
+// RetryPolicy.
+type job struct {
+	id          int64
+	op          op
+	aggregateID string
+	payload     map[string]interface{}
+
+	attempts           int
+	firstAttemptAt     time.Time
+	nextVisibleAt      time.Time
+	rescheduleEligible bool
+	lastError          string
+}
+
+// RetryPolicy governs how a failed job is backed off and when it is given
+// up on, borrowing the deployment-progress model from Nomad's
+// DesiredTransition.Reschedule and DeploymentState.ProgressDeadline: a job
+// is retried with exponential backoff until either it has been attempted
+// MaxAttempts times or ProgressDeadline has elapsed since its first
+// attempt, whichever comes first, at which point it moves to the
+// outbox_dead_letter table.
+type RetryPolicy struct {
+	MaxAttempts      int
+	InitialBackoff   time.Duration
+	MaxBackoff       time.Duration
+	Multiplier       float64
+	Jitter           float64 // fractional jitter applied as ±Jitter*backoff
+	ProgressDeadline time.Duration
+}
+
+// DefaultRetryPolicy returns conservative defaults suitable for transport
+// errors from the embedder or search index.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:      8,
+		InitialBackoff:   time.Second,
+		MaxBackoff:       5 * time.Minute,
+		Multiplier:       2.0,
+		Jitter:           0.2,
+		ProgressDeadline: 24 * time.Hour,
+	}
+}
+
+// Config configures the polling worker.
+type Config struct {
+	BatchSize   int
+	Interval    time.Duration
+	RetryPolicy RetryPolicy
+	// EmbedBatchSize is the maximum number of OpUpsertEntry/OpUpsertContext
+	// jobs grouped into a single BatchEmbedder.EmbedBatch call. Values <= 1
+	// put each job in a batch of its own: one Embed call per job, or one
+	// single-text EmbedBatch call when the embedder implements BatchEmbedder.
+	EmbedBatchSize int
+}
+
+// BatchEmbedder is an optional capability of an embedding provider: one
+// that can embed many texts in a single round trip. Worker prefers it over
+// per-job Embed calls to amortize embedding latency across a batch of
+// pending jobs.
+type BatchEmbedder interface {
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+var (
+	reschedulesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mycelian_outbox_reschedules_total",
+		Help: "Number of outbox jobs rescheduled after a reschedulable error.",
+	})
+	deadLetterTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "mycelian_outbox_dead_letter_total",
+		Help: "Number of outbox jobs moved to the dead-letter table.",
+	})
+	attemptsBeforeSuccess = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "mycelian_outbox_attempts_before_success",
+		Help:    "Number of attempts taken before a job succeeded.",
+		Buckets: prometheus.LinearBuckets(1, 1, 10),
+	})
+)
+
+// Worker polls the outbox table and drains ready jobs into the embedder and
+// search index.
+type Worker struct {
+	db       *sql.DB
+	log      zerolog.Logger
+	embedder emb.EmbeddingProvider
+	index    searchindex.Index
+	cfg      Config
+}
+
+// NewWorker constructs a Worker. A zero-value cfg.RetryPolicy is replaced
+// with DefaultRetryPolicy.
+func NewWorker(db *sql.DB, logger zerolog.Logger, embedder emb.EmbeddingProvider, index searchindex.Index, cfg Config) *Worker {
+	if cfg.RetryPolicy == (RetryPolicy{}) {
+		cfg.RetryPolicy = DefaultRetryPolicy()
+	}
+	return &Worker{db: db, log: logger, embedder: embedder, index: index, cfg: cfg}
+}
+
+// Run polls the outbox table at cfg.Interval until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := w.drain(ctx); err != nil {
+				w.log.Error().Err(err).Msg("outbox: drain failed")
+			}
+		}
+	}
+}
+
+// drain processes one batch of jobs whose NextVisibleAt has elapsed.
+// OpUpsertEntry/OpUpsertContext jobs are grouped into embedding batches of
+// up to cfg.EmbedBatchSize; every other op is handled one at a time.
+func (w *Worker) drain(ctx context.Context) error {
+	jobs, err := w.fetchReadyJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("fetching ready jobs: %w", err)
+	}
+
+	batchable, rest := partitionBatchable(jobs)
+	size := w.cfg.EmbedBatchSize
+	if size <= 0 {
+		size = 1
+	}
+	for start := 0; start < len(batchable); start += size {
+		end := start + size
+		if end > len(batchable) {
+			end = len(batchable)
+		}
+		w.processBatch(ctx, batchable[start:end])
+	}
+	for _, j := range rest {
+		w.process(ctx, j)
+	}
+	return nil
+}
+
+// partitionBatchable splits jobs into those eligible for the batch
+// embedding path (OpUpsertEntry/OpUpsertContext) and everything else.
+func partitionBatchable(jobs []job) (batchable, rest []job) {
+	for _, j := range jobs {
+		switch j.op {
+		case OpUpsertEntry, OpUpsertContext:
+			batchable = append(batchable, j)
+		default:
+			rest = append(rest, j)
+		}
+	}
+	return batchable, rest
+}
+
+// outcome is the decision process reaches for a job after handle runs.
+type outcome int
+
+const (
+	outcomeComplete outcome = iota
+	outcomeReschedule
+	outcomeDeadLetter
+)
+
+// process runs handle for j and applies the reschedule/dead-letter policy
+// on failure.
+func (w *Worker) process(ctx context.Context, j job) {
+	err := w.handle(ctx, j)
+	w.finalize(ctx, j, err)
+}
+
+// processBatch runs the batch embedding path for a group of
+// OpUpsertEntry/OpUpsertContext jobs, then applies the reschedule/
+// dead-letter policy to each job individually based on its own error (or
+// lack of one), so a partial batch failure only reschedules the jobs that
+// actually failed.
+func (w *Worker) processBatch(ctx context.Context, jobs []job) {
+	errs := w.handleBatch(ctx, jobs)
+	for i, j := range jobs {
+		w.finalize(ctx, j, errs[i])
+	}
+}
+
+// finalize applies decideOutcome's retry/dead-letter policy to j given the
+// error handle/handleBatch returned for it, and persists the result.
+func (w *Worker) finalize(ctx context.Context, j job, err error) {
+	result, j := decideOutcome(w.cfg, j, err, time.Now())
+
+	switch result {
+	case outcomeComplete:
+		attemptsBeforeSuccess.Observe(float64(j.attempts + 1))
+		if dbErr := w.completeJob(ctx, j); dbErr != nil {
+			w.log.Error().Err(dbErr).Int64("jobId", j.id).Msg("outbox: failed to mark job complete")
+		}
+	case outcomeDeadLetter:
+		deadLetterTotal.Inc()
+		if dbErr := w.deadLetterJob(ctx, j, err); dbErr != nil {
+			w.log.Error().Err(dbErr).Int64("jobId", j.id).Msg("outbox: failed to dead-letter job")
+		}
+	case outcomeReschedule:
+		reschedulesTotal.Inc()
+		if dbErr := w.rescheduleJob(ctx, j); dbErr != nil {
+			w.log.Error().Err(dbErr).Int64("jobId", j.id).Msg("outbox: failed to reschedule job")
+		}
+	}
+}
+
+// decideOutcome is the pure policy decision behind process: given the error
+// handle returned (nil on success), it decides whether the job is done,
+// should be rescheduled with backoff, or has exhausted its retry budget and
+// must move to the dead-letter table, returning the job with its retry
+// bookkeeping fields updated accordingly.
+func decideOutcome(cfg Config, j job, err error, now time.Time) (outcome, job) {
+	if err == nil {
+		return outcomeComplete, j
+	}
+
+	j.attempts++
+	j.rescheduleEligible = rescheduleEligible(err)
+	j.lastError = err.Error()
+	if j.firstAttemptAt.IsZero() {
+		j.firstAttemptAt = now
+	}
+
+	pastDeadline := cfg.RetryPolicy.ProgressDeadline > 0 && now.Sub(j.firstAttemptAt) > cfg.RetryPolicy.ProgressDeadline
+	exhausted := cfg.RetryPolicy.MaxAttempts > 0 && j.attempts >= cfg.RetryPolicy.MaxAttempts
+	if !j.rescheduleEligible || pastDeadline || exhausted {
+		return outcomeDeadLetter, j
+	}
+
+	j.nextVisibleAt = now.Add(nextBackoff(cfg.RetryPolicy, j.attempts-1))
+	return outcomeReschedule, j
+}
+
+// handle embeds and indexes a single job's payload.
+func (w *Worker) handle(ctx context.Context, j job) error {
+	switch j.op {
+	case OpUpsertEntry:
+		return w.handleUpsertEntry(ctx, j)
+	case OpUpsertContext:
+		return w.handleUpsertContext(ctx, j)
+	default:
+		return fmt.Errorf("unknown op %q for job %d", j.op, j.id)
+	}
+}
+
+func (w *Worker) handleUpsertEntry(ctx context.Context, j job) error {
+	text := textForEntry(j)
+	if text == "" {
+		return nil
+	}
+	vec, err := w.embedder.Embed(ctx, text)
+	if err != nil {
+		return err
+	}
+	return w.upsertJob(ctx, j, vec)
+}
+
+func (w *Worker) handleUpsertContext(ctx context.Context, j job) error {
+	text := textForContext(j)
+	if text == "" {
+		return nil
+	}
+	vec, err := w.embedder.Embed(ctx, text)
+	if err != nil {
+		return err
+	}
+	return w.upsertJob(ctx, j, vec)
+}
+
+func textForEntry(j job) string   { return preferredText(j.payload, "summary", "rawEntry") }
+func textForContext(j job) string { return preferredText(j.payload, "context") }
+
+// textForJob returns the text to embed for j, or an error if j's op is
+// unrecognized. An empty string (nil error) means the job should be
+// skipped: its payload has nothing worth embedding.
+func textForJob(j job) (string, error) {
+	switch j.op {
+	case OpUpsertEntry:
+		return textForEntry(j), nil
+	case OpUpsertContext:
+		return textForContext(j), nil
+	default:
+		return "", fmt.Errorf("unknown op %q for job %d", j.op, j.id)
+	}
+}
+
+// upsertJob indexes j's payload and vector against the op-appropriate
+// index method, treating isAlreadyExists as success.
+func (w *Worker) upsertJob(ctx context.Context, j job, vec []float32) error {
+	var err error
+	switch j.op {
+	case OpUpsertEntry:
+		err = w.index.UpsertEntry(ctx, j.aggregateID, vec, j.payload)
+	case OpUpsertContext:
+		err = w.index.UpsertContext(ctx, j.aggregateID, vec, j.payload)
+	default:
+		return fmt.Errorf("unknown op %q for job %d", j.op, j.id)
+	}
+	if err != nil && !isAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// handleBatch embeds and indexes a group of OpUpsertEntry/OpUpsertContext
+// jobs together, using BatchEmbedder.EmbedBatch when the configured
+// embedder supports it (falling back to one Embed call per text otherwise)
+// so the dominant per-job embedding latency is amortized across the batch.
+// A job skipped for empty text does not consume a slot in the batch. The
+// returned slice has one error per job in jobs, in the same order (nil on
+// success or skip). If the embedder returns a single all-or-nothing error
+// for the batch, every job that contributed a text fails; a BatchEmbedder
+// that instead returns a mismatched vector count is treated the same way,
+// since there is no way to attribute the shortfall to a specific job.
+func (w *Worker) handleBatch(ctx context.Context, jobs []job) []error {
+	results := make([]error, len(jobs))
+
+	texts := make([]string, 0, len(jobs))
+	indices := make([]int, 0, len(jobs))
+	for i, j := range jobs {
+		text, err := textForJob(j)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+		if text == "" {
+			continue
+		}
+		texts = append(texts, text)
+		indices = append(indices, i)
+	}
+	if len(texts) == 0 {
+		return results
+	}
+
+	vectors, err := w.embedBatch(ctx, texts)
+	if err != nil || len(vectors) != len(texts) {
+		if err == nil {
+			err = fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), len(texts))
+		}
+		for _, i := range indices {
+			results[i] = err
+		}
+		return results
+	}
+
+	for k, i := range indices {
+		if err := w.upsertJob(ctx, jobs[i], vectors[k]); err != nil {
+			results[i] = err
+		}
+	}
+	return results
+}
+
+// embedBatch embeds texts in one round trip when w.embedder implements
+// BatchEmbedder, falling back to one Embed call per text otherwise.
+func (w *Worker) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if be, ok := w.embedder.(BatchEmbedder); ok {
+		return be.EmbedBatch(ctx, texts)
+	}
+	vectors := make([][]float32, len(texts))
+	for i, t := range texts {
+		vec, err := w.embedder.Embed(ctx, t)
+		if err != nil {
+			return nil, err
+		}
+		vectors[i] = vec
+	}
+	return vectors, nil
+}
+
+// rescheduleEligible reports whether err should be retried with backoff
+// rather than moved straight to the dead-letter table. isAlreadyExists and
+// unknown-op errors are terminal; embedder/index transport errors are
+// reschedulable.
+func rescheduleEligible(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isAlreadyExists(err) {
+		return false
+	}
+	if strings.Contains(err.Error(), "unknown op") {
+		return false
+	}
+	return true
+}
+
+// nextBackoff computes the delay before retrying a job that has failed
+// attempt+1 times (attempt is 0 on the first retry), following
+// InitialBackoff * Multiplier^attempt capped at MaxBackoff, with up to
+// ±Jitter fractional jitter applied so retries from a batch don't thunder.
+func nextBackoff(p RetryPolicy, attempt int) time.Duration {
+	backoff := float64(p.InitialBackoff) * math.Pow(p.Multiplier, float64(attempt))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delta := backoff * p.Jitter
+		backoff += (rand.Float64()*2 - 1) * delta
+	}
+	if backoff < 0 {
+		backoff = 0
+	}
+	return time.Duration(backoff)
+}
+
+// isAlreadyExists classifies index errors that indicate the object was
+// already upserted by a previous, uncommitted attempt — these are terminal
+// successes, not failures.
+func isAlreadyExists(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "already exists") || strings.Contains(msg, "status code: 422")
+}
+
+// preferredText returns the first non-blank string value found in payload
+// across keys, in order. Non-string values are ignored.
+func preferredText(payload map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		v, ok := payload[k]
+		if !ok {
+			continue
+		}
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if strings.TrimSpace(s) != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// fetchReadyJobs loads up to cfg.BatchSize jobs whose NextVisibleAt has
+// elapsed, ordered oldest-first.
+func (w *Worker) fetchReadyJobs(ctx context.Context) ([]job, error) {
+	rows, err := w.db.QueryContext(ctx, `
+		SELECT id, op, aggregate_id, payload, attempts, first_attempt_at, last_error
+		FROM outbox
+		WHERE next_visible_at <= now()
+		ORDER BY id
+		LIMIT $1`, w.cfg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []job
+	for rows.Next() {
+		var j job
+		var payload []byte
+		var firstAttemptAt sql.NullTime
+		var lastError sql.NullString
+		if err := rows.Scan(&j.id, &j.op, &j.aggregateID, &payload, &j.attempts, &firstAttemptAt, &lastError); err != nil {
+			return nil, err
+		}
+		j.lastError = lastError.String
+		if firstAttemptAt.Valid {
+			j.firstAttemptAt = firstAttemptAt.Time
+		}
+		if err := unmarshalPayload(payload, &j.payload); err != nil {
+			return nil, fmt.Errorf("job %d: decoding payload: %w", j.id, err)
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+func marshalPayload(payload map[string]interface{}) ([]byte, error) {
+	return json.Marshal(payload)
+}
+
+func unmarshalPayload(data []byte, out *map[string]interface{}) error {
+	return json.Unmarshal(data, out)
+}
+
+// completeJob removes a successfully processed job from the outbox table.
+func (w *Worker) completeJob(ctx context.Context, j job) error {
+	_, err := w.db.ExecContext(ctx, `DELETE FROM outbox WHERE id = $1`, j.id)
+	return err
+}
+
+// rescheduleJob persists the updated attempt count and next visibility time
+// for a job that failed with a reschedulable error.
+func (w *Worker) rescheduleJob(ctx context.Context, j job) error {
+	_, err := w.db.ExecContext(ctx, `
+		UPDATE outbox
+		SET attempts = $2, first_attempt_at = $3, next_visible_at = $4, last_error = $5
+		WHERE id = $1`, j.id, j.attempts, j.firstAttemptAt, j.nextVisibleAt, j.lastError)
+	return err
+}
+
+// deadLetterJob moves a job that exhausted its retry budget (or failed
+// terminally) into outbox_dead_letter along with its last error, and
+// removes it from the outbox table.
+func (w *Worker) deadLetterJob(ctx context.Context, j job, lastErr error) error {
+	tx, err := w.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	payload, err := marshalPayload(j.payload)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox_dead_letter (id, op, aggregate_id, payload, attempts, first_attempt_at, last_error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		j.id, j.op, j.aggregateID, payload, j.attempts, j.firstAttemptAt, lastErr.Error()); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM outbox WHERE id = $1`, j.id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBatchEmbedder mocks an embedding provider that implements
+// BatchEmbedder as well as EmbeddingProvider.
+type MockBatchEmbedder struct {
+	MockEmbedder
+}
+
+func (m *MockBatchEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	args := m.Called(ctx, texts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]float32), args.Error(1)
+}
+
+func vec(n int) []float32 { return make([]float32, n) }
+
+func TestHandleBatch_UsesEmbedBatchWhenSupported(t *testing.T) {
+	worker, _, mockIndex := createTestWorker()
+	batchEmbedder := &MockBatchEmbedder{}
+	worker.embedder = batchEmbedder
+	ctx := context.Background()
+
+	jobs := []job{
+		{id: 1, op: OpUpsertEntry, aggregateID: "e1", payload: map[string]interface{}{"summary": "first"}},
+		{id: 2, op: OpUpsertContext, aggregateID: "c1", payload: map[string]interface{}{"context": "second"}},
+	}
+
+	batchEmbedder.On("EmbedBatch", ctx, []string{"first", "second"}).Return([][]float32{vec(3), vec(3)}, nil)
+	mockIndex.On("UpsertEntry", ctx, "e1", vec(3), jobs[0].payload).Return(nil)
+	mockIndex.On("UpsertContext", ctx, "c1", vec(3), jobs[1].payload).Return(nil)
+
+	errs := worker.handleBatch(ctx, jobs)
+
+	assert.Equal(t, []error{nil, nil}, errs)
+	batchEmbedder.AssertCalled(t, "EmbedBatch", ctx, []string{"first", "second"})
+	batchEmbedder.MockEmbedder.AssertNotCalled(t, "Embed")
+}
+
+func TestHandleBatch_SkipsEmptyTextsWithoutConsumingASlot(t *testing.T) {
+	worker, _, mockIndex := createTestWorker()
+	batchEmbedder := &MockBatchEmbedder{}
+	worker.embedder = batchEmbedder
+	ctx := context.Background()
+
+	jobs := []job{
+		{id: 1, op: OpUpsertEntry, aggregateID: "e1", payload: map[string]interface{}{"summary": "", "rawEntry": ""}},
+		{id: 2, op: OpUpsertEntry, aggregateID: "e2", payload: map[string]interface{}{"summary": "keep me"}},
+	}
+
+	batchEmbedder.On("EmbedBatch", ctx, []string{"keep me"}).Return([][]float32{vec(3)}, nil)
+	mockIndex.On("UpsertEntry", ctx, "e2", vec(3), jobs[1].payload).Return(nil)
+
+	errs := worker.handleBatch(ctx, jobs)
+
+	assert.Equal(t, []error{nil, nil}, errs)
+	mockIndex.AssertNotCalled(t, "UpsertEntry", ctx, "e1", mock.Anything, mock.Anything)
+}
+
+func TestHandleBatch_FallsBackToSequentialEmbedWhenUnsupported(t *testing.T) {
+	worker, mockEmbed, mockIndex := createTestWorker()
+	ctx := context.Background()
+
+	jobs := []job{
+		{id: 1, op: OpUpsertEntry, aggregateID: "e1", payload: map[string]interface{}{"summary": "first"}},
+		{id: 2, op: OpUpsertContext, aggregateID: "c1", payload: map[string]interface{}{"context": "second"}},
+	}
+
+	mockEmbed.On("Embed", ctx, "first").Return(vec(3), nil)
+	mockEmbed.On("Embed", ctx, "second").Return(vec(3), nil)
+	mockIndex.On("UpsertEntry", ctx, "e1", vec(3), jobs[0].payload).Return(nil)
+	mockIndex.On("UpsertContext", ctx, "c1", vec(3), jobs[1].payload).Return(nil)
+
+	errs := worker.handleBatch(ctx, jobs)
+
+	assert.Equal(t, []error{nil, nil}, errs)
+	mockEmbed.AssertCalled(t, "Embed", ctx, "first")
+	mockEmbed.AssertCalled(t, "Embed", ctx, "second")
+}
+
+func TestHandleBatch_AttributesPerJobUpsertFailure(t *testing.T) {
+	worker, _, mockIndex := createTestWorker()
+	batchEmbedder := &MockBatchEmbedder{}
+	worker.embedder = batchEmbedder
+	ctx := context.Background()
+
+	jobs := []job{
+		{id: 1, op: OpUpsertEntry, aggregateID: "e1", payload: map[string]interface{}{"summary": "first"}},
+		{id: 2, op: OpUpsertEntry, aggregateID: "e2", payload: map[string]interface{}{"summary": "second"}},
+	}
+
+	upsertErr := errors.New("connection refused")
+	batchEmbedder.On("EmbedBatch", ctx, []string{"first", "second"}).Return([][]float32{vec(3), vec(3)}, nil)
+	mockIndex.On("UpsertEntry", ctx, "e1", vec(3), jobs[0].payload).Return(nil)
+	mockIndex.On("UpsertEntry", ctx, "e2", vec(3), jobs[1].payload).Return(upsertErr)
+
+	errs := worker.handleBatch(ctx, jobs)
+
+	assert.NoError(t, errs[0])
+	assert.Equal(t, upsertErr, errs[1])
+}
+
+func TestHandleBatch_AllOrNothingEmbedErrorFailsEveryJob(t *testing.T) {
+	worker, _, _ := createTestWorker()
+	batchEmbedder := &MockBatchEmbedder{}
+	worker.embedder = batchEmbedder
+	ctx := context.Background()
+
+	jobs := []job{
+		{id: 1, op: OpUpsertEntry, aggregateID: "e1", payload: map[string]interface{}{"summary": "first"}},
+		{id: 2, op: OpUpsertEntry, aggregateID: "e2", payload: map[string]interface{}{"summary": "second"}},
+	}
+
+	embedErr := errors.New("embedding service unavailable")
+	batchEmbedder.On("EmbedBatch", ctx, []string{"first", "second"}).Return(nil, embedErr)
+
+	errs := worker.handleBatch(ctx, jobs)
+
+	assert.Equal(t, embedErr, errs[0])
+	assert.Equal(t, embedErr, errs[1])
+}
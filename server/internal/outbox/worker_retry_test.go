@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBackoff_ExponentialGrowthNoJitter(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Hour, Multiplier: 2.0, Jitter: 0}
+
+	assert.Equal(t, time.Second, nextBackoff(p, 0))
+	assert.Equal(t, 2*time.Second, nextBackoff(p, 1))
+	assert.Equal(t, 4*time.Second, nextBackoff(p, 2))
+}
+
+func TestNextBackoff_CapsAtMaxBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 2.0, Jitter: 0}
+
+	assert.Equal(t, 5*time.Second, nextBackoff(p, 10))
+}
+
+func TestNextBackoff_JitterStaysWithinBounds(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 10 * time.Second, MaxBackoff: time.Hour, Multiplier: 1.0, Jitter: 0.5}
+
+	for i := 0; i < 50; i++ {
+		d := nextBackoff(p, 0)
+		if d < 5*time.Second || d > 15*time.Second {
+			t.Fatalf("backoff %v outside expected jitter bounds [5s,15s]", d)
+		}
+	}
+}
+
+func TestRescheduleEligible_TerminalErrorsAreNotRescheduled(t *testing.T) {
+	assert.False(t, rescheduleEligible(nil))
+	assert.False(t, rescheduleEligible(errors.New("object already exists")))
+	assert.False(t, rescheduleEligible(errors.New("status code: 422")))
+	assert.False(t, rescheduleEligible(errors.New("unknown op \"bogus\" for job 1")))
+}
+
+func TestRescheduleEligible_TransportErrorsAreRescheduled(t *testing.T) {
+	assert.True(t, rescheduleEligible(errors.New("connection refused")))
+	assert.True(t, rescheduleEligible(errors.New("embedding service unavailable")))
+}
+
+func TestDecideOutcome_SuccessCompletes(t *testing.T) {
+	cfg := Config{RetryPolicy: DefaultRetryPolicy()}
+	result, _ := decideOutcome(cfg, job{id: 1}, nil, time.Now())
+	assert.Equal(t, outcomeComplete, result)
+}
+
+func TestDecideOutcome_ReschedulesTransportErrorUnderBudget(t *testing.T) {
+	cfg := Config{RetryPolicy: RetryPolicy{MaxAttempts: 8, ProgressDeadline: time.Hour, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 2.0}}
+	now := time.Now()
+	result, updated := decideOutcome(cfg, job{id: 1}, errors.New("connection refused"), now)
+
+	assert.Equal(t, outcomeReschedule, result)
+	assert.Equal(t, 1, updated.attempts)
+	assert.True(t, updated.rescheduleEligible)
+	assert.False(t, updated.nextVisibleAt.Before(now))
+}
+
+func TestDecideOutcome_DeadLettersTerminalError(t *testing.T) {
+	cfg := Config{RetryPolicy: DefaultRetryPolicy()}
+	result, updated := decideOutcome(cfg, job{id: 1}, errors.New("unknown op \"x\" for job 1"), time.Now())
+
+	assert.Equal(t, outcomeDeadLetter, result)
+	assert.False(t, updated.rescheduleEligible)
+}
+
+func TestDecideOutcome_DeadLettersAfterMaxAttempts(t *testing.T) {
+	cfg := Config{RetryPolicy: RetryPolicy{MaxAttempts: 3, ProgressDeadline: time.Hour, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 2.0}}
+	j := job{id: 1, attempts: 2, firstAttemptAt: time.Now()}
+
+	result, updated := decideOutcome(cfg, j, errors.New("connection refused"), time.Now())
+
+	assert.Equal(t, outcomeDeadLetter, result)
+	assert.Equal(t, 3, updated.attempts)
+}
+
+func TestDecideOutcome_DeadLettersPastProgressDeadline(t *testing.T) {
+	cfg := Config{RetryPolicy: RetryPolicy{MaxAttempts: 100, ProgressDeadline: time.Minute, InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 2.0}}
+	j := job{id: 1, attempts: 1, firstAttemptAt: time.Now().Add(-time.Hour)}
+
+	result, _ := decideOutcome(cfg, j, errors.New("connection refused"), time.Now())
+
+	assert.Equal(t, outcomeDeadLetter, result)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/mycelian/mycelian-memory/server/internal/model"
+	"github.com/mycelian/mycelian-memory/server/internal/searchindex"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -30,8 +31,8 @@ type MockIndex struct {
 	mock.Mock
 }
 
-func (m *MockIndex) Search(ctx context.Context, actorID, memoryID, query string, vec []float32, topKE int, alpha float32, includeRawEntries bool) ([]model.SearchHit, error) {
-	args := m.Called(ctx, actorID, memoryID, query, vec, topKE, alpha, includeRawEntries)
+func (m *MockIndex) Search(ctx context.Context, actorID, memoryID, query string, vec []float32, opts searchindex.SearchOptions) ([]model.SearchHit, error) {
+	args := m.Called(ctx, actorID, memoryID, query, vec, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -43,14 +44,22 @@ func (m *MockIndex) LatestContext(ctx context.Context, actorID, memoryID string)
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
 
-func (m *MockIndex) SearchContexts(ctx context.Context, actorID, memoryID, query string, vec []float32, topKC int, alpha float32) ([]model.ContextHit, error) {
-	args := m.Called(ctx, actorID, memoryID, query, vec, topKC, alpha)
+func (m *MockIndex) SearchContexts(ctx context.Context, actorID, memoryID, query string, vec []float32, opts searchindex.SearchContextsOptions) ([]model.ContextHit, error) {
+	args := m.Called(ctx, actorID, memoryID, query, vec, opts)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]model.ContextHit), args.Error(1)
 }
 
+func (m *MockIndex) ListMemoriesByVault(ctx context.Context, actorID, vaultID string) ([]string, error) {
+	args := m.Called(ctx, actorID, vaultID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
 func (m *MockIndex) UpsertEntry(ctx context.Context, id string, vector []float32, payload map[string]interface{}) error {
 	args := m.Called(ctx, id, vector, payload)
 	return args.Error(0)
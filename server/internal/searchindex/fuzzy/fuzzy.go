@@ -0,0 +1,153 @@
+// Package fuzzy implements typo-tolerant "find things by name" matching
+// across vaults, memories, entry summaries, and context shards. It is a
+// deliberately separate code path from the vector/hybrid semantic search in
+// the api package: bounded scan plus prefix/Levenshtein scoring, no
+// embedding call and no dependency on the vector index backend.
+package fuzzy
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// minScore is the lowest normalized similarity a non-prefix, non-substring
+// candidate may have and still be considered a match.
+const minScore = 0.5
+
+// Candidate is one named, scoped item eligible for fuzzy matching.
+type Candidate struct {
+	// ID identifies the underlying resource (vault ID, memory ID, entry ID,
+	// or context shard ID, depending on which Source produced it).
+	ID string
+	// Text is the name/title/summary matched against the query.
+	Text string
+	// Scope is the full breadcrumb path to Text, e.g.
+	// "Vault Alpha > Memory Beta > 2024-01-02T15:04:05Z", so a UI can render
+	// a hit without a follow-up lookup.
+	Scope string
+}
+
+// Source supplies the fuzzy-matchable candidates for one resource type,
+// scoped to a single actor and an optional namespace (e.g. a vault or
+// memory ID restricting the scan). Implementations live alongside the
+// concrete index/storage backend; this package only scores and ranks what
+// they return.
+type Source interface {
+	Candidates(ctx context.Context, actorID, namespace string) ([]Candidate, error)
+}
+
+// Match is one scored hit returned by Search.
+type Match struct {
+	ID    string
+	Scope string
+	Score float64
+}
+
+// Search scans up to scanLimit candidates from src, scores each against
+// text, and returns the top limit matches ranked by score descending.
+// truncated reports whether src had more candidates than scanLimit, so the
+// caller can surface "results may be incomplete" to the UI.
+func Search(ctx context.Context, src Source, actorID, namespace, text string, scanLimit, limit int) (matches []Match, truncated bool, err error) {
+	candidates, err := src.Candidates(ctx, actorID, namespace)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(candidates) > scanLimit {
+		candidates = candidates[:scanLimit]
+		truncated = true
+	}
+
+	scored := make([]Match, 0, len(candidates))
+	for _, c := range candidates {
+		s, ok := score(text, c.Text)
+		if !ok {
+			continue
+		}
+		scored = append(scored, Match{ID: c.ID, Scope: c.Scope, Score: s})
+	}
+	sort.SliceStable(scored, func(i, j int) bool { return scored[i].Score > scored[j].Score })
+	if len(scored) > limit {
+		scored = scored[:limit]
+	}
+	return scored, truncated, nil
+}
+
+// score returns candidate's similarity to query and whether it clears
+// minScore. An exact prefix match scores 1.0 and a substring match 0.8,
+// since they are the common "I remember how it starts/part of it" case;
+// everything else falls back to normalized Levenshtein similarity to
+// tolerate typos, taking the better of the whole candidate and its leading
+// runes of the query's length. A close typo can therefore score above a
+// substring match.
+func score(query, candidate string) (float64, bool) {
+	q := strings.ToLower(strings.TrimSpace(query))
+	c := strings.ToLower(candidate)
+	if q == "" {
+		return 0, false
+	}
+	if strings.HasPrefix(c, q) {
+		return 1.0, true
+	}
+	if strings.Contains(c, q) {
+		return 0.8, true
+	}
+
+	dist := levenshtein(q, c)
+	maxLen := len(q)
+	if len(c) > maxLen {
+		maxLen = len(c)
+	}
+	if maxLen == 0 {
+		return 0, false
+	}
+	similarity := 1.0 - float64(dist)/float64(maxLen)
+	qr, cr := []rune(q), []rune(c)
+	if len(cr) > len(qr) {
+		prefixDist := levenshtein(q, string(cr[:len(qr)]))
+		if s := 1.0 - float64(prefixDist)/float64(len(qr)); s > similarity {
+			similarity = s
+		}
+	}
+	if similarity < minScore {
+		return 0, false
+	}
+	return similarity, true
+}
+
+// levenshtein returns the edit distance between a and b using the standard
+// single-row dynamic-programming formulation.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			del := prev[j] + 1
+			ins := curr[j-1] + 1
+			sub := prev[j-1] + cost
+			curr[j] = min3(del, ins, sub)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func min3(a, b, c int) int {
+	m := a
+	if b < m {
+		m = b
+	}
+	if c < m {
+		m = c
+	}
+	return m
+}
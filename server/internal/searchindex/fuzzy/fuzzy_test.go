@@ -0,0 +1,105 @@
+package fuzzy
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type fakeSource struct {
+	candidates []Candidate
+	err        error
+}
+
+func (s *fakeSource) Candidates(ctx context.Context, actorID, namespace string) ([]Candidate, error) {
+	return s.candidates, s.err
+}
+
+func TestSearch_PrefixMatchRanksAboveTypo(t *testing.T) {
+	src := &fakeSource{candidates: []Candidate{
+		{ID: "1", Text: "Project Mercury", Scope: "Vault Alpha > Project Mercury"},
+		{ID: "2", Text: "Projekt Mercury", Scope: "Vault Alpha > Projekt Mercury"},
+		{ID: "3", Text: "Unrelated Thing", Scope: "Vault Alpha > Unrelated Thing"},
+	}}
+
+	matches, truncated, err := Search(context.Background(), src, "actor1", "", "Project", 10, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if truncated {
+		t.Fatalf("did not expect truncation")
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %d: %+v", len(matches), matches)
+	}
+	if matches[0].ID != "1" {
+		t.Fatalf("expected exact prefix match ranked first, got %+v", matches[0])
+	}
+}
+
+func TestSearch_ScanLimitTruncates(t *testing.T) {
+	src := &fakeSource{candidates: []Candidate{
+		{ID: "1", Text: "alpha"},
+		{ID: "2", Text: "alpha beta"},
+		{ID: "3", Text: "alpha gamma"},
+	}}
+
+	_, truncated, err := Search(context.Background(), src, "actor1", "", "alpha", 2, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !truncated {
+		t.Fatalf("expected truncated=true when candidates exceed scanLimit")
+	}
+}
+
+func TestSearch_ResultLimitCaps(t *testing.T) {
+	src := &fakeSource{candidates: []Candidate{
+		{ID: "1", Text: "alpha one"},
+		{ID: "2", Text: "alpha two"},
+		{ID: "3", Text: "alpha three"},
+	}}
+
+	matches, _, err := Search(context.Background(), src, "actor1", "", "alpha", 10, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected results capped at limit=1, got %d", len(matches))
+	}
+}
+
+func TestSearch_PropagatesSourceError(t *testing.T) {
+	src := &fakeSource{err: errors.New("backend unavailable")}
+	if _, _, err := Search(context.Background(), src, "actor1", "", "alpha", 10, 10); err == nil {
+		t.Fatalf("expected source error to propagate")
+	}
+}
+
+func TestSearch_NoCloseMatchExcluded(t *testing.T) {
+	src := &fakeSource{candidates: []Candidate{{ID: "1", Text: "completely different"}}}
+	matches, _, err := Search(context.Background(), src, "actor1", "", "zzz", 10, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 0 {
+		t.Fatalf("expected no matches for an unrelated candidate, got %+v", matches)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"kitten", "sitting", 3},
+		{"memory", "memory", 0},
+		{"abc", "", 3},
+	}
+	for _, tc := range cases {
+		if got := levenshtein(tc.a, tc.b); got != tc.want {
+			t.Fatalf("levenshtein(%q, %q) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package searchindex
+
+import "github.com/mycelian/mycelian-memory/server/internal/model"
+
+// SearchOptions bundles Index.Search's tuning knobs. Grouping them in a
+// struct instead of growing a positional parameter list means a new knob
+// (filter, explain, a boost, ...) never again risks silently swapping two
+// adjacent same-typed arguments (e.g. BM25Boost/VectorBoost) at a call site.
+type SearchOptions struct {
+	TopK              int
+	Alpha             float32
+	IncludeRawEntries bool
+	Filter            *model.Filter
+	Explain           bool
+	BM25Boost         float32
+	VectorBoost       float32
+	IncludeVectors    bool
+}
+
+// SearchContextsOptions bundles Index.SearchContexts's tuning knobs. See
+// SearchOptions.
+type SearchContextsOptions struct {
+	TopK        int
+	Alpha       float32
+	Filter      *model.Filter
+	Explain     bool
+	BM25Boost   float32
+	VectorBoost float32
+}
@@ -9,10 +9,52 @@ import (
 	"net/http"
 )
 
+// SearchCLIOptions bundles runSearchWithOptions's optional knobs so new ones
+// (fusion, alpha, boosts, variant, federation target) can be added without
+// growing a positional parameter list where two adjacent same-typed
+// arguments (e.g. BM25Boost/VectorBoost) could be transposed at a call site
+// and still compile. Zero-valued fields are left out of the request body so
+// the server applies its own defaults.
+type SearchCLIOptions struct {
+	// MemoryIDs, when non-empty, searches several memories at once. VaultID,
+	// when set, searches every memory in a vault the caller can access.
+	// memoryID (runSearchWithOptions's positional argument) is used when
+	// neither is set.
+	MemoryIDs []string
+	VaultID   string
+
+	// Fusion is the ranked-list fusion mode ("rrf", "weighted", "none").
+	Fusion string
+	// Alpha overrides the server's hybrid semantic weight; "weighted" fusion
+	// also scales entry scores by it.
+	Alpha float64
+	// K is the RRF rank-constant.
+	K int
+
+	// BM25Boost and VectorBoost multiply the lexical and semantic score
+	// components respectively before the alpha blend.
+	BM25Boost   float64
+	VectorBoost float64
+
+	// Variant forces the request onto a named SearchRouter variant instead
+	// of the server's default shadow-routed primary.
+	Variant string
+}
+
 func runSearch(apiURL, userID, memoryID, query string, topKE int, out io.Writer) error {
+	return runSearchWithOptions(apiURL, userID, memoryID, query, topKE, SearchCLIOptions{}, out)
+}
+
+// runSearchWithOptions is runSearch plus every optional knob exposed by the
+// server's search API: fusion, federation, hybrid-scoring tuning, and
+// variant routing. See SearchCLIOptions.
+func runSearchWithOptions(apiURL, userID, memoryID, query string, topKE int, opts SearchCLIOptions, out io.Writer) error {
 	if query == "" {
 		return fmt.Errorf("query cannot be empty")
 	}
+	if memoryID == "" && len(opts.MemoryIDs) == 0 && opts.VaultID == "" {
+		return fmt.Errorf("memoryID, memoryIDs, or vaultID is required")
+	}
 	// Note: userID is no longer in the request body, it's handled via authorization
 	if topKE <= 0 {
 		topKE = 5 // default
@@ -20,10 +62,35 @@ func runSearch(apiURL, userID, memoryID, query string, topKE int, out io.Writer)
 	topKC := 2 // default
 
 	payload := map[string]interface{}{
-		"memoryId": memoryID,
-		"query":    query,
-		"top_ke":   topKE,
-		"top_kc":   topKC,
+		"query":  query,
+		"top_ke": topKE,
+		"top_kc": topKC,
+	}
+	switch {
+	case opts.VaultID != "":
+		payload["vaultId"] = opts.VaultID
+	case len(opts.MemoryIDs) > 0:
+		payload["memoryId"] = opts.MemoryIDs
+	default:
+		payload["memoryId"] = memoryID
+	}
+	if opts.Fusion != "" {
+		payload["fusion"] = opts.Fusion
+	}
+	if opts.Alpha != 0 {
+		payload["alpha"] = opts.Alpha
+	}
+	if opts.K != 0 {
+		payload["k"] = opts.K
+	}
+	if opts.BM25Boost != 0 {
+		payload["bm25_boost"] = opts.BM25Boost
+	}
+	if opts.VectorBoost != 0 {
+		payload["vector_boost"] = opts.VectorBoost
+	}
+	if opts.Variant != "" {
+		payload["variant"] = opts.Variant
 	}
 	body, _ := json.Marshal(payload)
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, apiURL+"/v0/search", bytes.NewReader(body))
@@ -41,3 +41,111 @@ func TestRunSearch_Smoke(t *testing.T) {
 		t.Fatalf("unexpected output: %s", sb.String())
 	}
 }
+
+func TestRunSearchWithOptions_TuningParamsThreadThrough(t *testing.T) {
+	var captured struct {
+		MemoryID    string  `json:"memoryId"`
+		Query       string  `json:"query"`
+		Fusion      string  `json:"fusion"`
+		Alpha       float64 `json:"alpha"`
+		K           int     `json:"k"`
+		BM25Boost   float64 `json:"bm25_boost"`
+		VectorBoost float64 `json:"vector_boost"`
+		Variant     string  `json:"variant"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	opts := SearchCLIOptions{
+		Fusion:      "weighted",
+		Alpha:       0.75,
+		K:           60,
+		BM25Boost:   1.5,
+		VectorBoost: 0.5,
+		Variant:     "experimental",
+	}
+	var sb strings.Builder
+	if err := runSearchWithOptions(srv.URL, "u1", "m1", "hello", 3, opts, &sb); err != nil {
+		t.Fatalf("runSearchWithOptions: %v", err)
+	}
+
+	if captured.MemoryID != "m1" || captured.Query != "hello" {
+		t.Fatalf("unexpected base payload: %+v", captured)
+	}
+	if captured.Fusion != opts.Fusion {
+		t.Fatalf("fusion: got %q, want %q", captured.Fusion, opts.Fusion)
+	}
+	if captured.Alpha != opts.Alpha {
+		t.Fatalf("alpha: got %v, want %v", captured.Alpha, opts.Alpha)
+	}
+	if captured.K != opts.K {
+		t.Fatalf("k: got %v, want %v", captured.K, opts.K)
+	}
+	// bm25Boost and vectorBoost are adjacent same-typed fields; assert each
+	// against its own expected value (not just "both non-zero") so a
+	// transposition between them would fail this test.
+	if captured.BM25Boost != opts.BM25Boost {
+		t.Fatalf("bm25_boost: got %v, want %v", captured.BM25Boost, opts.BM25Boost)
+	}
+	if captured.VectorBoost != opts.VectorBoost {
+		t.Fatalf("vector_boost: got %v, want %v", captured.VectorBoost, opts.VectorBoost)
+	}
+	if captured.Variant != opts.Variant {
+		t.Fatalf("variant: got %q, want %q", captured.Variant, opts.Variant)
+	}
+}
+
+func TestRunSearchWithOptions_FederatedMemoryIDs(t *testing.T) {
+	var captured struct {
+		MemoryIDs []string `json:"memoryId"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	opts := SearchCLIOptions{MemoryIDs: []string{"m1", "m2"}}
+	var sb strings.Builder
+	if err := runSearchWithOptions(srv.URL, "u1", "", "hello", 3, opts, &sb); err != nil {
+		t.Fatalf("runSearchWithOptions: %v", err)
+	}
+	if len(captured.MemoryIDs) != 2 || captured.MemoryIDs[0] != "m1" || captured.MemoryIDs[1] != "m2" {
+		t.Fatalf("expected memoryId=[m1 m2], got %v", captured.MemoryIDs)
+	}
+}
+
+func TestRunSearchWithOptions_FederatedVaultID(t *testing.T) {
+	var captured struct {
+		VaultID string `json:"vaultId"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&captured); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"entries":[],"count":0}`))
+	}))
+	defer srv.Close()
+
+	opts := SearchCLIOptions{VaultID: "v1"}
+	var sb strings.Builder
+	if err := runSearchWithOptions(srv.URL, "u1", "", "hello", 3, opts, &sb); err != nil {
+		t.Fatalf("runSearchWithOptions: %v", err)
+	}
+	if captured.VaultID != "v1" {
+		t.Fatalf("expected vaultId=v1, got %q", captured.VaultID)
+	}
+}